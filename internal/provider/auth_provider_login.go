@@ -0,0 +1,241 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+const (
+	defaultAuthProviderType = "oidc"
+
+	// The timeout of a single token exchange with Central.
+	exchangeTokenTimeout = 30 * time.Second
+)
+
+// authProviderLogin is the configuration of the `auth_provider_login` block. The external token is either
+// given as is or obtained from the identity provider with OIDC client credentials.
+type authProviderLogin struct {
+	ProviderID    string
+	ProviderType  string
+	ExternalToken string
+	TokenURL      string
+	ClientID      string
+	ClientSecret  string
+	Scopes        []string
+}
+
+func authProviderLoginSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{"admin_password", "api_token"},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"auth_provider_id": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"auth_provider_type": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  defaultAuthProviderType,
+				},
+				"external_token": {
+					Type:      schema.TypeString,
+					Optional:  true,
+					Sensitive: true,
+				},
+				"token_url": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"client_id": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"client_secret": {
+					Type:      schema.TypeString,
+					Optional:  true,
+					Sensitive: true,
+				},
+				"scopes": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+			},
+		},
+	}
+}
+
+// readAuthProviderLogin returns the `auth_provider_login` block, or nil if it isn't set.
+func readAuthProviderLogin(data *schema.ResourceData) (*authProviderLogin, error) {
+	blocks := data.Get("auth_provider_login").([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil, nil
+	}
+
+	block := blocks[0].(map[string]interface{})
+	login := &authProviderLogin{
+		ProviderID:    block["auth_provider_id"].(string),
+		ProviderType:  block["auth_provider_type"].(string),
+		ExternalToken: block["external_token"].(string),
+		TokenURL:      block["token_url"].(string),
+		ClientID:      block["client_id"].(string),
+		ClientSecret:  block["client_secret"].(string),
+	}
+
+	for _, scope := range block["scopes"].([]interface{}) {
+		login.Scopes = append(login.Scopes, scope.(string))
+	}
+
+	clientCredentials := login.TokenURL != "" || login.ClientID != "" || login.ClientSecret != ""
+	switch {
+	case login.ExternalToken != "" && clientCredentials:
+		return nil, fmt.Errorf("only one of `external_token` or client credentials can be set in `auth_provider_login`")
+	case clientCredentials && (login.TokenURL == "" || login.ClientID == "" || login.ClientSecret == ""):
+		return nil, fmt.Errorf("`token_url`, `client_id` and `client_secret` must be set together in `auth_provider_login`")
+	case !clientCredentials && login.ExternalToken == "":
+		return nil, fmt.Errorf("one of `external_token` or client credentials must be set in `auth_provider_login`")
+	}
+
+	return login, nil
+}
+
+// externalTokenSource returns the source of the identity tokens that are exchanged for Central tokens.
+// Tokens obtained with client credentials are requested again by the oauth2 package when they expire. They're
+// requested with httpClient, so that the TLS and retry settings of the provider apply to the identity provider too.
+func (l authProviderLogin) externalTokenSource(ctx context.Context, httpClient *http.Client) oauth2.TokenSource {
+	if l.ExternalToken != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: l.ExternalToken})
+	}
+
+	config := clientcredentials.Config{
+		ClientID:     l.ClientID,
+		ClientSecret: l.ClientSecret,
+		TokenURL:     l.TokenURL,
+		Scopes:       l.Scopes,
+	}
+
+	return config.TokenSource(context.WithValue(ctx, oauth2.HTTPClient, httpClient))
+}
+
+// exchangeTokenSource obtains Central tokens by exchanging external tokens through an auth provider of Central.
+type exchangeTokenSource struct {
+	ctx      context.Context
+	client   *stackrox.APIClient
+	login    authProviderLogin
+	external oauth2.TokenSource
+}
+
+// newExchangeTokenSource returns a token source that caches the Central token and exchanges a new one
+// shortly before it expires. Exchanges are cancelled when ctx is done.
+func newExchangeTokenSource(ctx context.Context, client *stackrox.APIClient, login authProviderLogin, httpClient *http.Client) oauth2.TokenSource {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return oauth2.ReuseTokenSource(nil, &exchangeTokenSource{
+		ctx:      ctx,
+		client:   client,
+		login:    login,
+		external: login.externalTokenSource(ctx, httpClient),
+	})
+}
+
+func (s *exchangeTokenSource) Token() (*oauth2.Token, error) {
+	debug("exchanging token with auth provider " + s.login.ProviderID)
+
+	external, err := s.external.Token()
+	if err != nil {
+		return nil, fmt.Errorf("unable to obtain external token: %w", err)
+	}
+
+	// OIDC identity providers return the ID token next to the access token.
+	externalToken := external.AccessToken
+	if idToken, ok := external.Extra("id_token").(string); ok && idToken != "" {
+		externalToken = idToken
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, exchangeTokenTimeout)
+	defer cancel()
+
+	message := stackrox.V1ExchangeTokenRequest{
+		ExternalToken: externalToken,
+		Type:          s.login.ProviderType,
+		State:         s.login.ProviderID,
+	}
+	logMessage(message)
+
+	result, resp, err := s.client.AuthProviderServiceApi.ExchangeToken(ctx, message)
+	logResult(nil, resp, err)
+	err = newAPIError(resp, err)
+	if err != nil {
+		return nil, fmt.Errorf("unable to exchange token with auth provider %s: %w", s.login.ProviderID, err)
+	}
+
+	// Fall back to the lifetime of the external token if the expiry of the Central token is unknown.
+	expiry, ok := jwtExpiry(result.Token)
+	if !ok {
+		expiry = external.Expiry
+	}
+
+	return &oauth2.Token{
+		AccessToken: result.Token,
+		TokenType:   "Bearer",
+		Expiry:      expiry,
+	}, nil
+}
+
+// jwtExpiry returns the `exp` claim of a JWT. The signature isn't verified, the expiry is only used to know
+// when the token has to be refreshed.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,242 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+func testJWT(expiry time.Time, subject string) string {
+	claims := fmt.Sprintf(`{"sub":%q,"exp":%d}`, subject, expiry.Unix())
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+}
+
+// testExchangeServer emulates the token exchange and the groups API of Central.
+type testExchangeServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	lifetime  time.Duration
+	exchanges []stackrox.V1ExchangeTokenRequest
+	tokens    []string
+}
+
+func newTestExchangeServer(lifetime time.Duration) *testExchangeServer {
+	s := &testExchangeServer{lifetime: lifetime}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/v1/authProviders/exchangeToken":
+			var request stackrox.V1ExchangeTokenRequest
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			s.exchanges = append(s.exchanges, request)
+			token := testJWT(time.Now().Add(s.lifetime), fmt.Sprint(len(s.exchanges)))
+			_ = json.NewEncoder(w).Encode(stackrox.V1ExchangeTokenResponse{Token: token})
+		default:
+			s.tokens = append(s.tokens, r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"groups": []}`))
+		}
+	}))
+	return s
+}
+
+func TestJWTExpiry(t *testing.T) {
+	t.Parallel()
+
+	expiry := time.Unix(1700000000, 0)
+	actual, ok := jwtExpiry(testJWT(expiry, "user"))
+	assert.True(t, ok)
+	assert.Equal(t, expiry, actual)
+
+	_, ok = jwtExpiry("not-a-jwt")
+	assert.False(t, ok)
+}
+
+func TestAuthProviderLogin_cachesToken(t *testing.T) {
+	t.Parallel()
+
+	server := newTestExchangeServer(time.Hour)
+	defer server.Close()
+
+	login := authProviderLogin{ProviderID: "provider-id", ProviderType: "oidc", ExternalToken: "external-token"}
+	cli := NewAuthProviderLoginClientWrap(context.Background(), server.URL, login, newHTTPClient(nil, 0, 0))
+
+	for i := 0; i < 2; i++ {
+		_, _, err := cli.FindGroups(cli.AuthContext(context.Background()), "id")
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []stackrox.V1ExchangeTokenRequest{
+		{ExternalToken: "external-token", Type: "oidc", State: "provider-id"},
+	}, server.exchanges)
+	if assert.Len(t, server.tokens, 2) {
+		assert.Contains(t, server.tokens[0], "Bearer ")
+		assert.Equal(t, server.tokens[0], server.tokens[1])
+	}
+}
+
+func TestAuthProviderLogin_clientCredentialsUseProviderHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	// The identity provider's certificate is only trusted by the provider's HTTP client.
+	idp := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"idp-token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer idp.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(idp.Certificate())
+	httpClient := newHTTPClient(&tls.Config{RootCAs: roots}, 0, 0)
+
+	login := authProviderLogin{TokenURL: idp.URL + "/token", ClientID: "client", ClientSecret: "secret"}
+	token, err := login.externalTokenSource(context.Background(), httpClient).Token()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "idp-token", token.AccessToken)
+	}
+}
+
+func TestReadAuthProviderLogin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		block   map[string]interface{}
+		wantErr bool
+	}{
+		{name: "external token", block: map[string]interface{}{"auth_provider_id": "id", "external_token": "token"}},
+		{name: "client credentials", block: map[string]interface{}{
+			"auth_provider_id": "id", "token_url": "https://idp", "client_id": "client", "client_secret": "secret",
+		}},
+		{name: "no credentials", block: map[string]interface{}{"auth_provider_id": "id"}, wantErr: true},
+		{name: "incomplete client credentials", block: map[string]interface{}{
+			"auth_provider_id": "id", "client_id": "client",
+		}, wantErr: true},
+		{name: "both", block: map[string]interface{}{
+			"auth_provider_id": "id", "external_token": "token", "token_url": "https://idp", "client_id": "client", "client_secret": "secret",
+		}, wantErr: true},
+	}
+
+	providerSchema := Provider().(*schema.Provider).Schema
+	for _, tt := range tests {
+		data := schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{
+			"auth_provider_login": []interface{}{tt.block},
+		})
+
+		login, err := readAuthProviderLogin(data)
+		if tt.wantErr {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, "id", login.ProviderID, tt.name)
+			assert.Equal(t, defaultAuthProviderType, login.ProviderType, tt.name)
+		}
+	}
+}
+
+func TestAuthProviderLogin_refreshesExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	// Tokens that expire within the expiry delta of the oauth2 package are refreshed on every use.
+	server := newTestExchangeServer(time.Second)
+	defer server.Close()
+
+	login := authProviderLogin{ProviderID: "provider-id", ProviderType: "oidc", ExternalToken: "external-token"}
+	cli := NewAuthProviderLoginClientWrap(context.Background(), server.URL, login, newHTTPClient(nil, 0, 0))
+
+	for i := 0; i < 2; i++ {
+		_, _, err := cli.FindGroups(cli.AuthContext(context.Background()), "id")
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, server.exchanges, 2)
+	assert.NotEqual(t, server.tokens[0], server.tokens[1])
+}
+
+func TestAuthProviderLogin_clientCredentials(t *testing.T) {
+	t.Parallel()
+
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		if clientID != "client-id" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access-token","id_token":"id-token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer idp.Close()
+
+	server := newTestExchangeServer(time.Hour)
+	defer server.Close()
+
+	login := authProviderLogin{
+		ProviderID:   "provider-id",
+		ProviderType: "oidc",
+		TokenURL:     idp.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+	}
+	cli := NewAuthProviderLoginClientWrap(context.Background(), server.URL, login, newHTTPClient(nil, 0, 0))
+
+	_, _, err := cli.FindGroups(cli.AuthContext(context.Background()), "id")
+	assert.NoError(t, err)
+	if assert.Len(t, server.exchanges, 1) {
+		assert.Equal(t, "id-token", server.exchanges[0].ExternalToken)
+	}
+
+	login.ClientSecret = "wrong"
+	cli = NewAuthProviderLoginClientWrap(context.Background(), server.URL, login, newHTTPClient(nil, 0, 0))
+	_, _, err = cli.FindGroups(cli.AuthContext(context.Background()), "id")
+	assert.Error(t, err)
+}
+
+func TestAuthProviderLogin_exchangeFails(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid token","code":16,"message":"invalid token"}`))
+	}))
+	defer server.Close()
+
+	login := authProviderLogin{ProviderID: "provider-id", ProviderType: "oidc", ExternalToken: "external-token"}
+	cli := NewAuthProviderLoginClientWrap(context.Background(), server.URL, login, newHTTPClient(nil, 0, 0))
+
+	_, _, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(context.Background()), "id")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid token")
+	}
+}
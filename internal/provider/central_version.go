@@ -0,0 +1,104 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const centralVersionTimeout = time.Minute
+
+// centralFeatures maps the resources and attributes of the provider to the Central versions that support them.
+// Features that aren't listed are supported by every Central that speaks the API in swagger.json.
+var centralFeatures = map[string]version.Constraints{
+	"stackrox_sensor_upgrade_config": mustConstraints(">= 2.5.0"),
+}
+
+func mustConstraints(constraints string) version.Constraints {
+	result, err := version.NewConstraint(constraints)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// detectCentralVersion returns the version of the connected Central, or nil if it can't be determined.
+// An unknown version, e.g. of a development build, disables the feature checks.
+func detectCentralVersion(ctx context.Context, cli ClientWrap) *version.Version {
+	result, resp, err := cli.MetadataServiceApi.GetMetadata(cli.AuthContext(ctx))
+	logResult(result, resp, err)
+	if err != nil {
+		logAt(logLevelWarn, fmt.Sprintf("unable to detect the Central version: %v", err))
+		return nil
+	}
+
+	v, err := version.NewVersion(result.Version)
+	if err != nil {
+		logAt(logLevelWarn, fmt.Sprintf("unable to parse the Central version %q: %v", result.Version, err))
+		return nil
+	}
+
+	return v
+}
+
+// CentralVersion returns the version of the connected Central, or nil if it's unknown.
+func (c ClientWrap) CentralVersion() *version.Version {
+	return c.centralVersion
+}
+
+// checkCentralFeature returns an error if the connected Central doesn't support the feature.
+func (c ClientWrap) checkCentralFeature(feature string) error {
+	constraints, ok := centralFeatures[feature]
+	if !ok || c.centralVersion == nil {
+		return nil
+	}
+
+	// Release candidates and patch builds are treated like the release they belong to.
+	if !constraints.Check(c.centralVersion.Core()) {
+		return fmt.Errorf("%s isn't supported by Central %s, it requires Central %s", feature, c.centralVersion.Original(), constraints)
+	}
+
+	return nil
+}
+
+// checkCentralFeatures returns a CustomizeDiffFunc that fails the plan when the connected Central doesn't support
+// the resource, or one of the given attributes that is set in the configuration.
+func checkCentralFeatures(resourceType string, attributes ...string) schema.CustomizeDiffFunc {
+	return func(diff *schema.ResourceDiff, meta interface{}) error {
+		cli := meta.(ClientWrap)
+
+		if err := cli.checkCentralFeature(resourceType); err != nil {
+			return err
+		}
+
+		for _, attribute := range attributes {
+			if _, ok := diff.GetOk(attribute); !ok {
+				continue
+			}
+			if err := cli.checkCentralFeature(resourceType + "." + attribute); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
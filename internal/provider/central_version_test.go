@@ -0,0 +1,61 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectCentralVersion(t *testing.T) {
+	t.Parallel()
+
+	metadata := `{"version": "3.0.59.1"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metadata", r.URL.Path)
+		_, _ = w.Write([]byte(metadata))
+	}))
+	defer server.Close()
+
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+	v := detectCentralVersion(context.Background(), cli)
+	if assert.NotNil(t, v) {
+		assert.Equal(t, "3.0.59.1", v.Original())
+	}
+
+	metadata = `{"version": "3.0.59.x-42-g0123456789"}`
+	assert.Nil(t, detectCentralVersion(context.Background(), cli))
+}
+
+func TestClientWrap_checkCentralFeature(t *testing.T) {
+	t.Parallel()
+
+	cli := ClientWrap{}
+	assert.NoError(t, cli.checkCentralFeature("stackrox_sensor_upgrade_config"), "unknown versions aren't checked")
+
+	cli.centralVersion = version.Must(version.NewVersion("2.4.22"))
+	assert.Error(t, cli.checkCentralFeature("stackrox_sensor_upgrade_config"))
+	assert.NoError(t, cli.checkCentralFeature("stackrox_policy"))
+
+	cli.centralVersion = version.Must(version.NewVersion("2.5.0-rc.1"))
+	assert.NoError(t, cli.checkCentralFeature("stackrox_sensor_upgrade_config"))
+}
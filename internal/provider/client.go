@@ -18,43 +18,132 @@ package provider
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
+	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-version"
+	"golang.org/x/oauth2"
 
 	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
 )
 
-func newStackRoxClient(endpoint string) *stackrox.APIClient {
+func newStackRoxClient(endpoint string, httpClient *http.Client) *stackrox.APIClient {
 	cfg := stackrox.NewConfiguration()
 	cfg.BasePath = endpoint
+	cfg.HTTPClient = httpClient
 	return stackrox.NewAPIClient(cfg)
 }
 
-// ClientWrap holds the API Client and a context initialized with the Bearer token for accessing the API.
+// newHTTPClient returns the client shared by the generated API and the local extensions.
+// A nil tlsConfig keeps the default TLS settings, and maxRetries of zero disables retries.
+func newHTTPClient(tlsConfig *tls.Config, maxRetries int, retryMaxWait time.Duration) *http.Client {
+	transport := cleanhttp.DefaultPooledTransport()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	client := &http.Client{Transport: transport}
+	if maxRetries > 0 {
+		client.Transport = newRetryTransport(transport, maxRetries, retryMaxWait)
+	}
+	return client
+}
+
+// ClientWrap holds the API Client and the credentials for accessing the API.
+// Either an API token, a token exchanged through an auth provider, or a username and password pair is used.
 type ClientWrap struct {
 	*stackrox.APIClient
-	HTTPClient *http.Client
-	endpoint   string
-	username   string
-	password   string
+	HTTPClient  *http.Client
+	endpoint    string
+	username    string
+	password    string
+	token       string
+	tokenSource oauth2.TokenSource
+	stopCtx     context.Context
+
+	// The version of Central, or nil if it's unknown.
+	centralVersion *version.Version
 }
 
-func (c ClientWrap) BasicAuthContext() context.Context {
+// TimeoutContext returns a context that is cancelled when the timeout expires or when Terraform stops the provider,
+// e.g. on Ctrl-C.
+func (c ClientWrap) TimeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	parent := c.stopCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	return context.WithTimeout(parent, timeout)
+}
+
+// AuthContext returns ctx initialized with the credentials for accessing the API.
+// The API token and the auth provider login take precedence over basic authentication.
+func (c ClientWrap) AuthContext(ctx context.Context) context.Context {
+	if c.tokenSource != nil {
+		return context.WithValue(ctx, stackrox.ContextOAuth2, c.tokenSource)
+	}
+
+	if c.token != "" {
+		return context.WithValue(ctx, stackrox.ContextAccessToken, c.token)
+	}
+
 	basicAuth := stackrox.BasicAuth{
 		UserName: c.username,
 		Password: c.password,
 	}
 
-	return context.WithValue(context.Background(), stackrox.ContextBasicAuth, basicAuth)
+	return context.WithValue(ctx, stackrox.ContextBasicAuth, basicAuth)
 }
 
-func NewClientWrap(endpoint, username, password string) ClientWrap {
+// setAuthHeader sets the credentials on requests that don't go through the generated API client.
+func (c ClientWrap) setAuthHeader(req *http.Request) error {
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token()
+		if err != nil {
+			return err
+		}
+		token.SetAuthHeader(req)
+		return nil
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		return nil
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+	return nil
+}
+
+func NewClientWrap(endpoint, username, password string, httpClient *http.Client) ClientWrap {
 	return ClientWrap{
-		APIClient:  newStackRoxClient(endpoint),
-		HTTPClient: cleanhttp.DefaultClient(),
+		APIClient:  newStackRoxClient(endpoint, httpClient),
+		HTTPClient: httpClient,
 		endpoint:   endpoint,
 		username:   username,
 		password:   password,
 	}
 }
+
+func NewTokenClientWrap(endpoint, token string, httpClient *http.Client) ClientWrap {
+	return ClientWrap{
+		APIClient:  newStackRoxClient(endpoint, httpClient),
+		HTTPClient: httpClient,
+		endpoint:   endpoint,
+		token:      token,
+	}
+}
+
+// NewAuthProviderLoginClientWrap returns a client that authenticates with Central tokens exchanged through
+// an auth provider. Token exchanges are cancelled when ctx is done.
+func NewAuthProviderLoginClientWrap(ctx context.Context, endpoint string, login authProviderLogin, httpClient *http.Client) ClientWrap {
+	apiClient := newStackRoxClient(endpoint, httpClient)
+	return ClientWrap{
+		APIClient:   apiClient,
+		HTTPClient:  httpClient,
+		endpoint:    endpoint,
+		tokenSource: newExchangeTokenSource(ctx, apiClient, login, httpClient),
+	}
+}
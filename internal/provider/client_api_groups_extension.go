@@ -19,7 +19,6 @@ package provider
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -29,12 +28,14 @@ import (
 // FindGroups is a local extension of the generated API, and it provides missing functionality that returns
 // a slice of authentication groups filtered by the given authentication provider ID.
 func (c ClientWrap) FindGroups(ctx context.Context, authProviderId string) ([]stackrox.StorageGroup, *http.Response, error) {
-	req, err := http.NewRequest(http.MethodGet, c.endpoint+"/v1/groups", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/v1/groups", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req.SetBasicAuth(c.username, c.password)
+	if err := c.setAuthHeader(req); err != nil {
+		return nil, nil, err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -49,7 +50,7 @@ func (c ClientWrap) FindGroups(ctx context.Context, authProviderId string) ([]st
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, resp, fmt.Errorf(resp.Status)
+		return nil, resp, apiErrorFrom(resp, body)
 	}
 
 	type groupsType struct {
@@ -0,0 +1,122 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientWrap_FindGroupsSendsCredentials(t *testing.T) {
+	t.Parallel()
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"groups": []}`))
+	}))
+	defer server.Close()
+
+	cli := NewTokenClientWrap(server.URL, "secret-token", newHTTPClient(nil, 0, 0))
+	_, _, err := cli.FindGroups(cli.AuthContext(context.Background()), "id")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer secret-token", authorization)
+
+	cli = NewClientWrap(server.URL, "admin", "secret-password", newHTTPClient(nil, 0, 0))
+	_, _, err = cli.FindGroups(cli.AuthContext(context.Background()), "id")
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic YWRtaW46c2VjcmV0LXBhc3N3b3Jk", authorization)
+}
+
+func TestClientWrap_TLSSettings(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"groups": []}`))
+	}))
+	defer server.Close()
+
+	caCertPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	tests := []struct {
+		name     string
+		settings tlsSettings
+		wantErr  bool
+	}{
+		{name: "untrusted", settings: tlsSettings{}, wantErr: true},
+		{name: "custom CA", settings: tlsSettings{CACertPEM: caCertPEM}},
+		{name: "insecure", settings: tlsSettings{InsecureSkipVerify: true}},
+	}
+
+	for _, tt := range tests {
+		tlsConfig, err := newTLSConfig(tt.settings)
+		assert.NoError(t, err)
+
+		cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(tlsConfig, 0, 0))
+		_, _, err = cli.FindGroups(cli.AuthContext(context.Background()), "id")
+		if tt.wantErr {
+			assert.Error(t, err, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
+
+func TestNewTLSConfig_invalidCA(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTLSConfig(tlsSettings{CACertPEM: "not a certificate"})
+	assert.Error(t, err)
+}
+
+func TestClientWrap_TimeoutContextFollowsStopContext(t *testing.T) {
+	t.Parallel()
+
+	stopCtx, stop := context.WithCancel(context.Background())
+
+	cli := NewTokenClientWrap("https://localhost", "token", newHTTPClient(nil, 0, 0))
+	cli.stopCtx = stopCtx
+
+	ctx, cancel := cli.TimeoutContext(time.Minute)
+	defer cancel()
+
+	stop()
+	<-ctx.Done()
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
+
+func TestClientWrap_FindGroupsHonoursContext(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+	ctx, cancel := cli.TimeoutContext(10 * time.Millisecond)
+	defer cancel()
+
+	_, _, err := cli.FindGroups(cli.AuthContext(ctx), "id")
+	assert.Error(t, err)
+}
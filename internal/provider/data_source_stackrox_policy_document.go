@@ -0,0 +1,171 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// dataSourceStackRoxPolicyDocument renders the canonical JSON of a policy, which can be used as `policy_json` of a
+// `stackrox_policy`. The policy is composed of the `source_json`, the structured attributes, which override the
+// source, and the `override_json`, which overrides both. Criteria are merged one by one, all other fields are
+// replaced as a whole. Only configured values override, e.g. `disabled = false`, `privileged = "false"` or
+// `process_baseline_violation = false`.
+func dataSourceStackRoxPolicyDocument() *schema.Resource {
+	result := stackRoxPolicyAttributesSchema()
+
+	// Without defaults, an explicit `false` can be told apart from an unset attribute.
+	result["disabled"].Default = nil
+	criteria := result["policy_criteria"].Elem.(*schema.Resource).Schema
+	criteria["process_baseline_violation"].Default = nil
+
+	result["source_json"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validateStackRoxPolicyJSON,
+	}
+	result["override_json"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validateStackRoxPolicyJSON,
+	}
+	result["json"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		Read:   stackRoxPolicyDocumentRead,
+		Schema: result,
+	}
+}
+
+func stackRoxPolicyDocumentRead(data *schema.ResourceData, meta interface{}) error {
+	debug("Rendering policy document")
+
+	document := map[string]interface{}{}
+
+	if source := data.Get("source_json").(string); source != "" {
+		if err := stackRoxMergePolicyJSON(document, source); err != nil {
+			return fmt.Errorf("error merging source_json: %v", err)
+		}
+	}
+
+	policy, err := stackRoxPolicyMessageFromAttributes(data)
+	if err != nil {
+		return err
+	}
+	fields, err := stackRoxCanonicalPolicyFields(policy)
+	if err != nil {
+		return err
+	}
+	if disabled, ok := data.GetOkExists("disabled"); ok {
+		fields["disabled"] = disabled.(bool)
+	}
+	if enabled, ok := data.GetOkExists("policy_criteria.0.process_baseline_violation"); ok {
+		criteria, _ := fields["fields"].(map[string]interface{})
+		if criteria == nil {
+			criteria = map[string]interface{}{}
+			fields["fields"] = criteria
+		}
+		criteria["whitelistEnabled"] = enabled.(bool)
+	}
+	stackRoxMergePolicyFields(document, fields)
+
+	if override := data.Get("override_json").(string); override != "" {
+		if err := stackRoxMergePolicyJSON(document, override); err != nil {
+			return fmt.Errorf("error merging override_json: %v", err)
+		}
+	}
+
+	merged, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	// Parse the merged document again, so that the result is canonical.
+	policy, err = stackRoxPolicyFromJSON(string(merged))
+	if err != nil {
+		return err
+	}
+	canonical, err := stackRoxCanonicalPolicyJSON(policy)
+	if err != nil {
+		return err
+	}
+
+	if err := data.Set("json", canonical); err != nil {
+		return err
+	}
+	data.SetId(strconv.Itoa(hashcode.String(canonical)))
+
+	return nil
+}
+
+// stackRoxMergePolicyJSON merges the policy JSON, which may be an export of one policy, into the document. All
+// fields that are set in the JSON are merged, even if they have their default value.
+func stackRoxMergePolicyJSON(document map[string]interface{}, policyJSON string) error {
+	if _, err := stackRoxPolicyFromJSON(policyJSON); err != nil {
+		return err
+	}
+
+	raw, err := stackRoxPolicyJSONFields(policyJSON)
+	if err != nil {
+		return err
+	}
+
+	// The unsupported fields were reported by validateStackRoxPolicyJSON.
+	for _, field := range stackRoxUnknownPolicyFields(raw) {
+		delete(raw, field)
+	}
+
+	fields := map[string]interface{}{}
+	for k, v := range raw {
+		var value interface{}
+		if err := json.Unmarshal(v, &value); err != nil {
+			return err
+		}
+		if value != nil {
+			fields[k] = value
+		}
+	}
+
+	stackRoxMergePolicyFields(document, fields)
+
+	return nil
+}
+
+// stackRoxMergePolicyFields merges the fields of a policy into the document. The criteria, i.e. the
+// `fields` of the policy, are merged one by one, all other fields replace the fields of the document.
+func stackRoxMergePolicyFields(document, fields map[string]interface{}) {
+	for k, v := range fields {
+		criteria, ok := v.(map[string]interface{})
+		base, baseOK := document[k].(map[string]interface{})
+		if k == "fields" && ok && baseOK {
+			for criterion, value := range criteria {
+				base[criterion] = value
+			}
+			continue
+		}
+
+		document[k] = v
+	}
+}
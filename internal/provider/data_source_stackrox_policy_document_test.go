@@ -0,0 +1,233 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+func testStackRoxPolicyDocument(t *testing.T, raw map[string]interface{}) stackrox.StoragePolicy {
+	data := schema.TestResourceDataRaw(t, dataSourceStackRoxPolicyDocument().Schema, raw)
+	require.NoError(t, stackRoxPolicyDocumentRead(data, nil))
+	assert.NotEmpty(t, data.Id())
+
+	policy, err := stackRoxPolicyFromJSON(data.Get("json").(string))
+	require.NoError(t, err)
+
+	return policy
+}
+
+func TestStackRoxPolicyDocument_attributes(t *testing.T) {
+	t.Parallel()
+
+	raw := testStackRoxPolicyConfigRaw(map[string]interface{}{
+		"privileged": "true",
+		"cvss":       ">= 7",
+	})
+	raw["enforcement_actions"] = []interface{}{"SCALE_TO_ZERO_ENFORCEMENT"}
+
+	policy := testStackRoxPolicyDocument(t, raw)
+
+	// The document is the same policy as the one stackrox_policy creates from the attributes.
+	data := schema.TestResourceDataRaw(t, resourceStackRoxPolicy().Schema, raw)
+	expected, err := stackRoxPolicyMessageFrom(data)
+	require.NoError(t, err)
+
+	expectedJSON, err := stackRoxCanonicalPolicyJSON(expected)
+	require.NoError(t, err)
+	actualJSON, err := stackRoxCanonicalPolicyJSON(policy)
+	require.NoError(t, err)
+	assert.Equal(t, expectedJSON, actualJSON)
+}
+
+func TestStackRoxPolicyDocument_sourceAndOverride(t *testing.T) {
+	t.Parallel()
+
+	source := `{
+  "name": "Base Policy",
+  "description": "base description",
+  "rationale": "base rationale",
+  "remediation": "base remediation",
+  "categories": ["Privileges"],
+  "lifecycleStages": ["DEPLOY"],
+  "severity": "LOW_SEVERITY",
+  "fields": {
+    "privileged": true,
+    "user": "0"
+  }
+}`
+
+	policy := testStackRoxPolicyDocument(t, map[string]interface{}{
+		"source_json": source,
+		"name":        "Team Policy",
+		"policy_criteria": []interface{}{
+			map[string]interface{}{"user": "root"},
+		},
+		"override_json": `{"severity": "CRITICAL_SEVERITY"}`,
+	})
+
+	assert.Equal(t, "Team Policy", policy.Name)
+	assert.Equal(t, "base description", policy.Description)
+	assert.Equal(t, []string{"Privileges"}, policy.Categories)
+	assert.Equal(t, stackrox.StorageSeverity("CRITICAL_SEVERITY"), policy.Severity)
+
+	// Criteria are merged one by one.
+	assert.Equal(t, "true", stackRoxTerraformBoolFromMessage(policy.Fields.Privileged))
+	assert.Equal(t, "root", policy.Fields.User)
+}
+
+func TestStackRoxPolicyDocument_falseValues(t *testing.T) {
+	t.Parallel()
+
+	source := `{
+  "name": "Base Policy",
+  "disabled": true,
+  "lifecycleStages": ["DEPLOY"],
+  "severity": "LOW_SEVERITY",
+  "fields": {
+    "privileged": true,
+    "noScanExists": true,
+    "whitelistEnabled": true
+  }
+}`
+
+	data := schema.TestResourceDataRaw(t, dataSourceStackRoxPolicyDocument().Schema, map[string]interface{}{
+		"source_json": source,
+		"disabled":    false,
+		"policy_criteria": []interface{}{
+			map[string]interface{}{
+				"privileged":        "false",
+				"read_only_root_fs": "false",
+				"host_mount": []interface{}{
+					map[string]interface{}{"read_only": false},
+				},
+				"process_baseline_violation": false,
+			},
+		},
+		"override_json": `{"fields": {"noScanExists": false}}`,
+	})
+	require.NoError(t, stackRoxPolicyDocumentRead(data, nil))
+
+	rendered := data.Get("json").(string)
+	assert.Contains(t, rendered, `"readOnlyRootFs":false`)
+	assert.Contains(t, rendered, `"hostMountPolicy":{"readOnly":false}`)
+
+	// The rendered document is the policy that stackrox_policy creates from it.
+	policy, err := stackRoxPolicyFromJSON(rendered)
+	require.NoError(t, err)
+	assert.False(t, policy.Disabled)
+	assert.Equal(t, "false", stackRoxTerraformBoolFromMessage(policy.Fields.Privileged))
+	assert.Equal(t, "false", stackRoxTerraformBoolFromMessage(policy.Fields.ReadOnlyRootFs))
+	assert.Equal(t, "false", stackRoxTerraformBoolFromMessage(policy.Fields.NoScanExists))
+	assert.False(t, policy.Fields.WhitelistEnabled)
+	if assert.NotNil(t, policy.Fields.HostMountPolicy) {
+		assert.Equal(t, "false", stackRoxTerraformBoolFromMessage(policy.Fields.HostMountPolicy.ReadOnly))
+	}
+
+	canonical, err := stackRoxCanonicalPolicyJSON(policy)
+	require.NoError(t, err)
+	assert.Equal(t, rendered, canonical)
+}
+
+func TestStackRoxMergePolicyFields(t *testing.T) {
+	t.Parallel()
+
+	document := map[string]interface{}{
+		"name":       "base",
+		"categories": []interface{}{"A", "B"},
+		"fields":     map[string]interface{}{"privileged": true},
+	}
+
+	stackRoxMergePolicyFields(document, map[string]interface{}{
+		"categories": []interface{}{"C"},
+		"fields":     map[string]interface{}{"user": "root"},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"name":       "base",
+		"categories": []interface{}{"C"},
+		"fields":     map[string]interface{}{"privileged": true, "user": "root"},
+	}, document)
+}
+
+func TestAccStackRoxPolicyDocument_basic(t *testing.T) {
+	resourceName := acctest.RandomWithPrefix("testacc-policy")
+
+	var policy stackrox.StoragePolicy
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStackRoxPolicyDocumentConfig(resourceName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxPolicyExists(resourceName, &policy),
+					resource.TestCheckResourceAttrPair(
+						testAccStackRoxPolicyAddress(resourceName), "canonical_json",
+						fmt.Sprintf("data.stackrox_policy_document.%s", resourceName), "json",
+					),
+				),
+			},
+		},
+		CheckDestroy: testAccCheckStackRoxPolicyWasDestroyed(resourceName),
+	})
+}
+
+func testAccStackRoxPolicyDocumentConfig(resourceName string) string {
+	const config = testAccProviderConfig + `
+data "stackrox_policy_document" "%s" {
+  source_json = <<EOF
+{
+  "name": "%s",
+  "description": "base description",
+  "rationale": "base rationale",
+  "remediation": "base remediation",
+  "disabled": true,
+  "categories": ["Privileges"],
+  "lifecycleStages": ["DEPLOY"],
+  "severity": "LOW_SEVERITY",
+  "fields": {
+    "privileged": true
+  }
+}
+EOF
+
+  severity = "HIGH_SEVERITY"
+
+  policy_criteria {
+    user = "root"
+  }
+}
+
+resource "stackrox_policy" "%s" {
+  policy_json = data.stackrox_policy_document.%s.json
+}
+`
+	return fmt.Sprintf(config,
+		resourceName, resourceName, resourceName, resourceName,
+	)
+}
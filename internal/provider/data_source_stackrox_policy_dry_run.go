@@ -0,0 +1,179 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// dataSourceStackRoxPolicyDryRun evaluates a policy against the current deployments without creating it. The
+// policy is given as JSON, e.g. the `json` of a `stackrox_policy_document`, which is known at plan time, so that
+// the dry run reports the deployments a new or changed policy would hit before it's applied. Each alert is one
+// deployment, which may have several violations. If `max_violations` is set, reading the data source fails if the
+// policy would raise more violations across all deployments.
+func dataSourceStackRoxPolicyDryRun() *schema.Resource {
+	return &schema.Resource{
+		Read: stackRoxPolicyDryRunRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(2 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"policy_json": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateStackRoxPolicyJSON,
+			},
+			"max_violations": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"alert_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"violation_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"alert": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"deployment": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"violations": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"excluded": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"deployment": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"exclusion": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func stackRoxPolicyDryRunRead(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxPolicyDryRunRead")
+
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	policyJSON := data.Get("policy_json").(string)
+	message, err := stackRoxPolicyFromJSON(policyJSON)
+	if err != nil {
+		return err
+	}
+	logMessage(message)
+
+	result, resp, err := cli.PolicyServiceApi.DryRunPolicy(cli.AuthContext(ctx), message)
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
+	if err != nil {
+		return err
+	}
+
+	canonical, err := stackRoxCanonicalPolicyJSON(message)
+	if err != nil {
+		return err
+	}
+	data.SetId(strconv.Itoa(hashcode.String(canonical)))
+
+	if err := stackRoxPolicyDryRunSetState(data, result); err != nil {
+		return err
+	}
+
+	violations := stackRoxDryRunViolationCount(result)
+	if maxViolations, ok := data.GetOkExists("max_violations"); ok && violations > maxViolations.(int) {
+		return fmt.Errorf("policy %q would raise %d violations in %d deployments, which exceeds max_violations of %d",
+			message.Name, violations, len(result.Alerts), maxViolations.(int))
+	}
+
+	return nil
+}
+
+func stackRoxPolicyDryRunSetState(data *schema.ResourceData, src stackrox.V1DryRunResponse) error {
+	alerts := make([]interface{}, 0, len(src.Alerts))
+	for _, alert := range src.Alerts {
+		alerts = append(alerts, map[string]interface{}{
+			"deployment": alert.Deployment,
+			"violations": alert.Violations,
+		})
+	}
+
+	excluded := make([]interface{}, 0, len(src.Excluded))
+	for _, e := range src.Excluded {
+		excluded = append(excluded, map[string]interface{}{
+			"deployment": e.Deployment,
+			"exclusion":  e.Whitelist.Name,
+		})
+	}
+
+	if err := data.Set("alert_count", len(src.Alerts)); err != nil {
+		return err
+	}
+	if err := data.Set("violation_count", stackRoxDryRunViolationCount(src)); err != nil {
+		return err
+	}
+	if err := data.Set("alert", alerts); err != nil {
+		return err
+	}
+	if err := data.Set("excluded", excluded); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// stackRoxDryRunViolationCount returns the number of violations across all alerts of the dry run.
+func stackRoxDryRunViolationCount(src stackrox.V1DryRunResponse) int {
+	count := 0
+	for _, alert := range src.Alerts {
+		count += len(alert.Violations)
+	}
+
+	return count
+}
@@ -0,0 +1,148 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+func newTestDryRunServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/policies/dryrun", r.URL.Path)
+
+		var policy stackrox.StoragePolicy
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&policy))
+		assert.Equal(t, "Privileged Container", policy.Name)
+		assert.Empty(t, policy.Id)
+
+		_ = json.NewEncoder(w).Encode(stackrox.V1DryRunResponse{
+			Alerts: []stackrox.V1DryRunResponseAlert{
+				{Deployment: "web", Violations: []string{
+					"Container 'nginx' is privileged",
+					"Container 'sidecar' is privileged",
+				}},
+				{Deployment: "db", Violations: []string{"Container 'postgres' is privileged"}},
+			},
+			Excluded: []stackrox.DryRunResponseExcluded{
+				{Deployment: "kube-proxy", Whitelist: stackrox.StorageWhitelist{Name: "kube-system"}},
+			},
+		})
+	}))
+}
+
+func TestStackRoxPolicyDryRun(t *testing.T) {
+	t.Parallel()
+
+	server := newTestDryRunServer(t)
+	defer server.Close()
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+
+	data := schema.TestResourceDataRaw(t, dataSourceStackRoxPolicyDryRun().Schema, map[string]interface{}{
+		"policy_json": testStackRoxPolicyJSON,
+	})
+	require.NoError(t, stackRoxPolicyDryRunRead(data, cli))
+
+	assert.NotEmpty(t, data.Id())
+	assert.Equal(t, 2, data.Get("alert_count"))
+	assert.Equal(t, 3, data.Get("violation_count"))
+	assert.Equal(t, "web", data.Get("alert.0.deployment"))
+	assert.Equal(t, []interface{}{"Container 'postgres' is privileged"}, data.Get("alert.1.violations"))
+	assert.Equal(t, "kube-proxy", data.Get("excluded.0.deployment"))
+	assert.Equal(t, "kube-system", data.Get("excluded.0.exclusion"))
+}
+
+func TestStackRoxPolicyDryRun_maxViolations(t *testing.T) {
+	t.Parallel()
+
+	server := newTestDryRunServer(t)
+	defer server.Close()
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+
+	data := schema.TestResourceDataRaw(t, dataSourceStackRoxPolicyDryRun().Schema, map[string]interface{}{
+		"policy_json":    testStackRoxPolicyJSON,
+		"max_violations": 3,
+	})
+	assert.NoError(t, stackRoxPolicyDryRunRead(data, cli))
+
+	// The violations of all deployments are counted.
+	data = schema.TestResourceDataRaw(t, dataSourceStackRoxPolicyDryRun().Schema, map[string]interface{}{
+		"policy_json":    testStackRoxPolicyJSON,
+		"max_violations": 2,
+	})
+	err := stackRoxPolicyDryRunRead(data, cli)
+	assert.EqualError(t, err, `policy "Privileged Container" would raise 3 violations in 2 deployments, which exceeds max_violations of 2`)
+}
+
+func TestAccStackRoxPolicyDryRun_basic(t *testing.T) {
+	resourceName := acctest.RandomWithPrefix("testacc-policy")
+	address := fmt.Sprintf("data.stackrox_policy_dry_run.%s", resourceName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStackRoxPolicyDryRunConfig(resourceName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(address, "alert_count"),
+				),
+			},
+		},
+	})
+}
+
+func testAccStackRoxPolicyDryRunConfig(resourceName string) string {
+	const config = testAccProviderConfig + `
+data "stackrox_policy_document" "%s" {
+  name             = "%s"
+  description      = "fake description"
+  rationale        = "fake rationale"
+  remediation      = "fake remediation"
+  categories       = [
+    "Privileges"
+  ]
+  lifecycle_stages = [
+    "DEPLOY"
+  ]
+  severity         = "HIGH_SEVERITY"
+
+  policy_criteria {
+    privileged = true
+  }
+}
+
+data "stackrox_policy_dry_run" "%s" {
+  policy_json = data.stackrox_policy_document.%s.json
+}
+`
+	return fmt.Sprintf(config,
+		resourceName, resourceName, resourceName, resourceName,
+	)
+}
@@ -0,0 +1,129 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// apiErrorKind classifies the errors returned by Central.
+type apiErrorKind string
+
+const (
+	apiErrorUnknown          apiErrorKind = "error"
+	apiErrorNotFound         apiErrorKind = "not found"
+	apiErrorConflict         apiErrorKind = "conflict"
+	apiErrorPermissionDenied apiErrorKind = "permission denied"
+	apiErrorValidation       apiErrorKind = "invalid request"
+)
+
+// gRPC status codes used by Central in the `code` field of its error responses.
+const (
+	grpcInvalidArgument  = 3
+	grpcNotFound         = 5
+	grpcAlreadyExists    = 6
+	grpcPermissionDenied = 7
+	grpcUnauthenticated  = 16
+)
+
+// apiError is an error response of Central, including the message from the response body.
+type apiError struct {
+	Kind       apiErrorKind
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s: %s", e.Kind, e.Status)
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Kind, e.Message, e.Status)
+}
+
+// runtimeError is the body of error responses sent by Central.
+type runtimeError struct {
+	Error   string `json:"error"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newAPIError translates the result of a call to the generated API into an error that carries Central's message.
+// It returns nil when the call succeeded, and err unchanged when no response was received, e.g. on network errors.
+func newAPIError(resp *http.Response, err error) error {
+	if err == nil && (resp == nil || resp.StatusCode < http.StatusMultipleChoices) {
+		return nil
+	}
+
+	var apiErr *apiError
+	if resp == nil || errors.As(err, &apiErr) {
+		return err
+	}
+
+	var body []byte
+	var openAPIErr stackrox.GenericOpenAPIError
+	if errors.As(err, &openAPIErr) {
+		body = openAPIErr.Body()
+	}
+
+	return apiErrorFrom(resp, body)
+}
+
+// apiErrorFrom classifies an error response of Central by its status and the gRPC code in its body.
+func apiErrorFrom(resp *http.Response, body []byte) *apiError {
+	result := &apiError{
+		Kind:       apiErrorUnknown,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+	}
+
+	var rtErr runtimeError
+	if len(body) > 0 && json.Unmarshal(body, &rtErr) == nil {
+		result.Message = rtErr.Message
+		if result.Message == "" {
+			result.Message = rtErr.Error
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || rtErr.Code == grpcNotFound:
+		result.Kind = apiErrorNotFound
+	case resp.StatusCode == http.StatusConflict || rtErr.Code == grpcAlreadyExists:
+		result.Kind = apiErrorConflict
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized ||
+		rtErr.Code == grpcPermissionDenied || rtErr.Code == grpcUnauthenticated:
+		result.Kind = apiErrorPermissionDenied
+	case resp.StatusCode == http.StatusBadRequest || rtErr.Code == grpcInvalidArgument:
+		result.Kind = apiErrorValidation
+	}
+
+	return result
+}
+
+func isAPIErrorKind(err error, kind apiErrorKind) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Kind == kind
+}
+
+func isNotFound(err error) bool {
+	return isAPIErrorKind(err, apiErrorNotFound)
+}
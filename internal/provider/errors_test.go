@@ -0,0 +1,126 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIError_includesCentralMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		kind    apiErrorKind
+		message string
+	}{
+		{
+			name:    "not found",
+			status:  http.StatusNotFound,
+			body:    `{"error":"policy with id 'x' does not exist","code":5,"message":"policy with id 'x' does not exist"}`,
+			kind:    apiErrorNotFound,
+			message: "policy with id 'x' does not exist",
+		},
+		{
+			name:    "conflict",
+			status:  http.StatusInternalServerError,
+			body:    `{"error":"policy with name 'x' already exists","code":6}`,
+			kind:    apiErrorConflict,
+			message: "policy with name 'x' already exists",
+		},
+		{
+			name:    "permission denied",
+			status:  http.StatusForbidden,
+			body:    `{"error":"not authorized","code":7,"message":"not authorized"}`,
+			kind:    apiErrorPermissionDenied,
+			message: "not authorized",
+		},
+		{
+			name:    "validation",
+			status:  http.StatusBadRequest,
+			body:    `{"error":"invalid policy","code":3,"message":"invalid policy: name is required"}`,
+			kind:    apiErrorValidation,
+			message: "invalid policy: name is required",
+		},
+		{
+			name:   "no body",
+			status: http.StatusBadGateway,
+			kind:   apiErrorUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(tt.status)
+			_, _ = w.Write([]byte(tt.body))
+		}))
+
+		cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+		_, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(context.Background()), "x")
+		err = newAPIError(resp, err)
+		server.Close()
+
+		var apiErr *apiError
+		if assert.True(t, errors.As(err, &apiErr), tt.name) {
+			assert.Equal(t, tt.kind, apiErr.Kind, tt.name)
+			assert.Equal(t, tt.status, apiErr.StatusCode, tt.name)
+			assert.Equal(t, tt.message, apiErr.Message, tt.name)
+			assert.Contains(t, err.Error(), tt.message, tt.name)
+		}
+		assert.Equal(t, tt.kind == apiErrorNotFound, isNotFound(err), tt.name)
+	}
+}
+
+func TestNewAPIError_success(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, newAPIError(&http.Response{StatusCode: http.StatusOK}, nil))
+	assert.NoError(t, newAPIError(nil, nil))
+}
+
+func TestNewAPIError_keepsTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("connection refused")
+	assert.Equal(t, err, newAPIError(nil, err))
+	assert.False(t, isNotFound(err))
+}
+
+func TestClientWrap_FindGroupsReturnsAPIError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"not authorized","code":7,"message":"not authorized"}`))
+	}))
+	defer server.Close()
+
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+	_, resp, err := cli.FindGroups(cli.AuthContext(context.Background()), "id")
+	err = newAPIError(resp, err)
+	assert.True(t, isAPIErrorKind(err, apiErrorPermissionDenied))
+	assert.Contains(t, err.Error(), "not authorized")
+}
@@ -18,24 +18,108 @@ package provider
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 )
 
+// Log levels understood by Terraform's log filter (TF_LOG).
+const (
+	logLevelTrace = "TRACE"
+	logLevelDebug = "DEBUG"
+	logLevelWarn  = "WARN"
+)
+
+const redacted = "********"
+
+// sensitiveFields are the JSON keys of API messages whose values are never logged.
+// Besides the fields of the generated models, this includes the keys of the
+// `StorageAuthProvider.Config` map.
+var sensitiveFields = map[string]bool{
+	"accessKey":        true,
+	"apiKey":           true,
+	"clientKeyPem":     true,
+	"client_secret":    true,
+	"externalToken":    true,
+	"httpToken":        true,
+	"idp_cert_pem":     true,
+	"idp_metadata_url": true,
+	"licenseKey":       true,
+	"oauthToken":       true,
+	"password":         true,
+	"privateKeyPem":    true,
+	"secretAccessKey":  true,
+	"secretKey":        true,
+	"serviceAccount":   true,
+	"token":            true,
+}
+
+func logAt(level string, msg interface{}) {
+	log.Printf("[%s] ########## STACKROX ##########: %v", level, msg)
+}
+
 func debug(msg interface{}) {
-	log.Println("########## STACKROX ##########:", msg)
+	logAt(logLevelDebug, msg)
 }
 
+// logMessage logs an API message at the TRACE level, with sensitive fields redacted.
 func logMessage(message interface{}) {
-	data, err := json.MarshalIndent(message, "", "    ")
+	data, err := json.MarshalIndent(redact(message), "", "    ")
 	if err != nil {
-		panic(err)
+		logAt(logLevelWarn, fmt.Sprintf("unable to log message of type %T: %v", message, err))
+		return
 	}
-	debug(string(data))
+	logAt(logLevelTrace, string(data))
 }
 
+// logResult logs the outcome of an API call. Only the request line and the status of the response
+// are logged, because the headers carry the credentials.
 func logResult(result interface{}, resp *http.Response, err error) {
-	debug(err)
-	debug(resp)
-	debug(result)
+	if err != nil {
+		debug(err)
+	}
+	if resp != nil {
+		if resp.Request != nil {
+			debug(fmt.Sprintf("%s %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status))
+		} else {
+			debug(resp.Status)
+		}
+	}
+	logMessage(result)
+}
+
+// redact returns a copy of message as generic JSON values, with the values of sensitive fields masked.
+// Messages that can't be converted are returned unchanged, so that they fail when they're marshaled.
+func redact(message interface{}) interface{} {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return message
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return message
+	}
+
+	return redactValue(value)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[key] {
+				if field != nil && field != "" {
+					v[key] = redacted
+				}
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactValue(e)
+		}
+	}
+	return value
 }
@@ -0,0 +1,82 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	notifier := stackrox.StorageNotifier{
+		Name: "splunk",
+		Splunk: &stackrox.StorageSplunk{
+			HttpEndpoint: "https://example.com",
+			HttpToken:    "hec-token",
+		},
+	}
+
+	result := redact(notifier).(map[string]interface{})
+	splunk := result["splunk"].(map[string]interface{})
+	assert.Equal(t, "splunk", result["name"])
+	assert.Equal(t, "https://example.com", splunk["httpEndpoint"])
+	assert.Equal(t, redacted, splunk["httpToken"])
+
+	authProvider := stackrox.StorageAuthProvider{
+		Config: map[string]string{
+			"idp_metadata_url": "https://example.com/metadata?secret",
+			"sp_issuer":        "https://example.com/",
+		},
+	}
+
+	config := redact(authProvider).(map[string]interface{})["config"].(map[string]interface{})
+	assert.Equal(t, redacted, config["idp_metadata_url"])
+	assert.Equal(t, "https://example.com/", config["sp_issuer"])
+}
+
+// Not parallel, because it redirects the global logger.
+func TestLogResult_omitsHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	resp := &http.Response{
+		Status: "200 OK",
+		Request: &http.Request{
+			Method: http.MethodGet,
+			URL:    &url.URL{Path: "/v1/groups"},
+			Header: http.Header{"Authorization": []string{"Bearer secret"}},
+		},
+	}
+	logResult(stackrox.StorageDockerConfig{Password: "secret"}, resp, nil)
+	logMessage(func() {})
+
+	assert.Contains(t, buf.String(), "[DEBUG]")
+	assert.Contains(t, buf.String(), "GET /v1/groups: 200 OK")
+	assert.Contains(t, buf.String(), "[WARN]")
+	assert.NotContains(t, buf.String(), "secret")
+}
@@ -17,49 +17,216 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
-
-	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
 )
 
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"endpoint": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ROX_ENDPOINT", nil),
 			},
 			"admin_password": {
-				Type:      schema.TypeString,
-				Required:  true,
-				Sensitive: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("ROX_ADMIN_PASSWORD", nil),
+				ConflictsWith: []string{"api_token", "auth_provider_login"},
+			},
+			"api_token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("ROX_API_TOKEN", nil),
+				ConflictsWith: []string{"admin_password", "auth_provider_login"},
+			},
+			"auth_provider_login": authProviderLoginSchema(),
+			"config_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ROX_CONFIG_FILE", nil),
+			},
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_file"},
+			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("ROX_CA_CERT_FILE", nil),
+				ConflictsWith: []string{"ca_cert_pem"},
+			},
+			"client_cert_pem": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"client_key_pem"},
+			},
+			"client_key_pem": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				RequiredWith: []string{"client_cert_pem"},
+			},
+			"tls_server_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ROX_SERVER_NAME", nil),
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ROX_INSECURE_CLIENT_SKIP_TLS_VERIFY", false),
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultMaxRetries,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultRetryMaxWait.String(),
+				ValidateFunc: validateDuration,
 			},
 		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"stackrox_policy_document": dataSourceStackRoxPolicyDocument(),
+			"stackrox_policy_dry_run":  dataSourceStackRoxPolicyDryRun(),
+		},
 		ResourcesMap: map[string]*schema.Resource{
+			"stackrox_default_policy":         resourceStackRoxDefaultPolicy(),
 			"stackrox_generic_image_registry": resourceStackRoxGenericImageRegistry(),
 			"stackrox_kubernetes_cluster":     resourceStackRoxKubernetesCluster(),
 			"stackrox_okta_auth_provider":     resourceStackRoxOktaAuthProvider(),
 			"stackrox_policy":                 resourceStackRoxPolicy(),
+			"stackrox_sensor_upgrade_config":  resourceStackRoxSensorUpgradeConfig(),
 			"stackrox_splunk_integration":     resourceStackRoxSplunkIntegration(),
 		},
-		ConfigureFunc: providerConfigure,
 	}
+
+	provider.ConfigureFunc = func(data *schema.ResourceData) (interface{}, error) {
+		return providerConfigure(data, provider.StopContext())
+	}
+
+	return provider
 }
 
-func providerConfigure(data *schema.ResourceData) (interface{}, error) {
-	endpoint := data.Get("endpoint").(string)
-	username := "admin"
-	password := data.Get("admin_password").(string)
+// providerConfigure builds the API client. Requests made with the client are cancelled when stopCtx is done.
+func providerConfigure(data *schema.ResourceData, stopCtx context.Context) (interface{}, error) {
+	config := providerConfig{
+		Endpoint:      data.Get("endpoint").(string),
+		AdminPassword: data.Get("admin_password").(string),
+		APIToken:      data.Get("api_token").(string),
+		CACertPEM:     data.Get("ca_cert_pem").(string),
+		CACertFile:    data.Get("ca_cert_file").(string),
+	}
 
-	client := NewClientWrap(endpoint, username, password)
+	// Values that aren't set by the configuration or the environment are read from the config file.
+	if path := data.Get("config_file").(string); path != "" {
+		fileConfig, err := readProviderConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		config = config.withDefaults(fileConfig)
+	}
 
-	// Always disable automatic sensor upgrades.
-	_, _, err := client.SensorUpgradeServiceApi.UpdateSensorUpgradeConfig(client.BasicAuthContext(), stackrox.V1UpdateSensorUpgradeConfigRequest{
-		Config: stackrox.StorageSensorUpgradeConfig{
-			EnableAutoUpgrade: false,
-		},
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("`endpoint` must be set")
+	}
+	config.Endpoint = normalizeEndpoint(config.Endpoint)
+
+	caCertPEM, err := config.caCert()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(tlsSettings{
+		CACertPEM:          caCertPEM,
+		ClientCertPEM:      data.Get("client_cert_pem").(string),
+		ClientKeyPEM:       data.Get("client_key_pem").(string),
+		ServerName:         data.Get("tls_server_name").(string),
+		InsecureSkipVerify: data.Get("insecure_skip_verify").(bool),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	retryMaxWait, err := time.ParseDuration(data.Get("retry_max_wait").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := newHTTPClient(tlsConfig, data.Get("max_retries").(int), retryMaxWait)
+	username := "admin"
+
+	login, err := readAuthProviderLogin(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var client ClientWrap
+	switch {
+	case login != nil && (config.APIToken != "" || config.AdminPassword != ""):
+		return nil, fmt.Errorf("only one of `admin_password`, `api_token` or `auth_provider_login` can be set")
+	case config.APIToken != "" && config.AdminPassword != "":
+		return nil, fmt.Errorf("only one of `admin_password` or `api_token` can be set")
+	case login != nil:
+		client = NewAuthProviderLoginClientWrap(stopCtx, config.Endpoint, *login, httpClient)
+	case config.APIToken != "":
+		client = NewTokenClientWrap(config.Endpoint, config.APIToken, httpClient)
+	case config.AdminPassword != "":
+		client = NewClientWrap(config.Endpoint, username, config.AdminPassword, httpClient)
+	default:
+		return nil, fmt.Errorf("one of `admin_password`, `api_token` or `auth_provider_login` must be set")
+	}
+
+	client.stopCtx = stopCtx
+
+	// Log in eagerly, so that invalid credentials are reported when the provider is configured.
+	if client.tokenSource != nil {
+		if _, err := client.tokenSource.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := client.TimeoutContext(centralVersionTimeout)
+	defer cancel()
+	client.centralVersion = detectCentralVersion(ctx, client)
+
+	return client, nil
+}
+
+func validateDuration(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+
+	if _, err := time.ParseDuration(v); err != nil {
+		errors = append(errors, fmt.Errorf("%s is not a valid duration: %v", k, err))
+	}
+
+	return
+}
+
+// normalizeEndpoint turns an endpoint in the `host:port` form of `ROX_ENDPOINT`, as used by roxctl, into a URL. The
+// trailing slash is trimmed, since the paths of the API are appended to the endpoint.
+func normalizeEndpoint(endpoint string) string {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
 
-	return client, err
+	return strings.TrimRight(endpoint, "/")
 }
@@ -0,0 +1,87 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// providerConfig holds the settings used to connect to Central.
+// It's also the format of the JSON file referenced by the `config_file` argument, for example:
+//
+//	{
+//	  "endpoint": "https://central.example.com:443",
+//	  "api_token": "..."
+//	}
+type providerConfig struct {
+	Endpoint      string `json:"endpoint"`
+	AdminPassword string `json:"admin_password"`
+	APIToken      string `json:"api_token"`
+	CACertPEM     string `json:"ca_cert_pem"`
+	CACertFile    string `json:"ca_cert_file"`
+}
+
+func readProviderConfigFile(path string) (providerConfig, error) {
+	result := providerConfig{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return result, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+
+	return result, nil
+}
+
+// withDefaults fills the unset values of c from defaults.
+// The credentials and the CA are each taken as a whole, so that values from different sources aren't mixed.
+func (c providerConfig) withDefaults(defaults providerConfig) providerConfig {
+	if c.Endpoint == "" {
+		c.Endpoint = defaults.Endpoint
+	}
+
+	if c.AdminPassword == "" && c.APIToken == "" {
+		c.AdminPassword = defaults.AdminPassword
+		c.APIToken = defaults.APIToken
+	}
+
+	if c.CACertPEM == "" && c.CACertFile == "" {
+		c.CACertPEM = defaults.CACertPEM
+		c.CACertFile = defaults.CACertFile
+	}
+
+	return c
+}
+
+// caCert returns the PEM encoded CA bundle, reading it from `ca_cert_file` if necessary.
+func (c providerConfig) caCert() (string, error) {
+	if c.CACertPEM != "" || c.CACertFile == "" {
+		return c.CACertPEM, nil
+	}
+
+	data, err := ioutil.ReadFile(c.CACertFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading CA certificate file: %v", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,57 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProviderConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "stackrox-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(path, []byte(`{"endpoint": "https://central.example.com", "api_token": "token"}`), 0600)
+	assert.NoError(t, err)
+
+	config, err := readProviderConfigFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, providerConfig{Endpoint: "https://central.example.com", APIToken: "token"}, config)
+
+	_, err = readProviderConfigFile(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestProviderConfig_withDefaults(t *testing.T) {
+	t.Parallel()
+
+	defaults := providerConfig{Endpoint: "https://file.example.com", APIToken: "file-token"}
+
+	config := providerConfig{AdminPassword: "password"}.withDefaults(defaults)
+	assert.Equal(t, providerConfig{Endpoint: "https://file.example.com", AdminPassword: "password"}, config)
+
+	config = providerConfig{Endpoint: "https://central.example.com"}.withDefaults(defaults)
+	assert.Equal(t, providerConfig{Endpoint: "https://central.example.com", APIToken: "file-token"}, config)
+}
@@ -17,11 +17,11 @@
 package provider
 
 import (
-	"fmt"
 	"log"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/stretchr/testify/assert"
@@ -38,39 +38,58 @@ func TestProvider_impl(t *testing.T) {
 	var _ = Provider()
 }
 
+func TestNormalizeEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"central.example.com:443":          "https://central.example.com:443",
+		"central.example.com:443/":         "https://central.example.com:443",
+		"https://central.example.com:443":  "https://central.example.com:443",
+		"https://central.example.com:443/": "https://central.example.com:443",
+		"http://localhost:8080":            "http://localhost:8080",
+	}
+
+	for endpoint, expected := range tests {
+		assert.Equal(t, expected, normalizeEndpoint(endpoint), endpoint)
+	}
+}
+
 func testAccProviders() map[string]terraform.ResourceProvider {
 	return map[string]terraform.ResourceProvider{
 		"stackrox": Provider(),
 	}
 }
 
+// testAccClientWrap configures the provider the same way Terraform does, i.e. from the
+// `ROX_*` environment variables.
 func testAccClientWrap() ClientWrap {
-	return NewClientWrap(testAccEndpoint(), "admin", testAccPassword())
+	p := Provider().(*schema.Provider)
+	if err := p.Configure(terraform.NewResourceConfigRaw(map[string]interface{}{})); err != nil {
+		log.Fatalf("error configuring provider: %v", err)
+	}
+	return p.Meta().(ClientWrap)
 }
 
 func testAccStackRoxProviderConfig() string {
-	return fmt.Sprintf(testAccProviderConfig, testAccEndpoint(), testAccPassword())
+	return testAccProviderConfig
 }
 
-func testAccEndpoint() string {
-	return lookupEnvOrFail("stackrox_api_endpoint")
-}
+// testAccPreCheck skips tests that talk to Central outside of acceptance test runs, and fails
+// them when the provider environment variables aren't set.
+func testAccPreCheck(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
 
-func testAccPassword() string {
-	return lookupEnvOrFail("stackrox_api_admin_password")
-}
+	if os.Getenv("ROX_ENDPOINT") == "" {
+		t.Fatal("ROX_ENDPOINT must be set for acceptance tests")
+	}
 
-func lookupEnvOrFail(key string) string {
-	val, ok := os.LookupEnv(key)
-	if !ok {
-		log.Fatalf("missing environment variable: %s", key)
+	if os.Getenv("ROX_ADMIN_PASSWORD") == "" && os.Getenv("ROX_API_TOKEN") == "" {
+		t.Fatal("ROX_ADMIN_PASSWORD or ROX_API_TOKEN must be set for acceptance tests")
 	}
-	return val
 }
 
 const testAccProviderConfig = `
-provider "stackrox" {
-  endpoint       = "%s"
-  admin_password = "%s"
-}
+provider "stackrox" {}
 `
@@ -0,0 +1,342 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/antihax/optional"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// resourceStackRoxDefaultPolicy adopts an existing policy, e.g. a default policy of StackRox, by name. Only the
+// settings of the policy are managed, i.e. whether it's disabled, the notifiers, the enforcement actions and the
+// exclusions. The notifiers, enforcement actions and exclusions are replaced by the configured ones, so leaving them
+// out removes them, while `disabled` is left as it is unless it's configured. The policy isn't deleted on destroy,
+// instead the settings it had when it was adopted are restored.
+func resourceStackRoxDefaultPolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: stackRoxDefaultPolicyCreate,
+		Read:   stackRoxDefaultPolicyRead,
+		Update: stackRoxDefaultPolicyUpdate,
+		Delete: stackRoxDefaultPolicyDelete,
+		Importer: &schema.ResourceImporter{
+			State: stackRoxDefaultPolicyImportState,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"disabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"notifiers": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"enforcement_actions": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(enforcementActionNames(), false),
+				},
+				Set: schema.HashString,
+			},
+			"exclusion": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     stackRoxExclusionResource(),
+			},
+			// The canonical JSON of the policy when it was adopted, whose settings are restored on destroy.
+			"original_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func stackRoxDefaultPolicyCreate(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxDefaultPolicyCreate")
+
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	policy, err := stackRoxFindPolicyByName(ctx, cli, data.Get("name").(string))
+	if err != nil {
+		return err
+	}
+
+	original, err := stackRoxCanonicalPolicyJSON(policy)
+	if err != nil {
+		return err
+	}
+
+	// Set the ID right away, so that the original settings are restored even if updating the policy fails.
+	data.SetId(policy.Id)
+	if err := data.Set("original_json", original); err != nil {
+		return err
+	}
+
+	if err := stackRoxDefaultPolicyApply(ctx, cli, data, policy); err != nil {
+		return err
+	}
+
+	return stackRoxDefaultPolicyRead(data, meta)
+}
+
+func stackRoxDefaultPolicyRead(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxDefaultPolicyRead")
+
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(ctx), data.Id())
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
+
+	// If the resource does not exist, inform Terraform. We want to immediately
+	// return here to prevent further processing.
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Update the local state.
+	return stackRoxDefaultPolicySetState(data, result)
+}
+
+func stackRoxDefaultPolicyUpdate(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxDefaultPolicyUpdate")
+
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
+	if !data.HasChanges("disabled", "notifiers", "enforcement_actions", "exclusion") {
+		return stackRoxDefaultPolicyRead(data, meta)
+	}
+
+	result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(ctx), data.Id())
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
+	if err != nil {
+		return err
+	}
+
+	if err := stackRoxDefaultPolicyApply(ctx, cli, data, result); err != nil {
+		return err
+	}
+
+	return stackRoxDefaultPolicyRead(data, meta)
+}
+
+func stackRoxDefaultPolicyDelete(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxDefaultPolicyDelete: " + data.Id())
+
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
+	result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(ctx), data.Id())
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
+
+	// Destroy should be idempotent. There's nothing to restore if the policy is gone.
+	if isNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	original, err := stackRoxPolicyFromJSON(data.Get("original_json").(string))
+	if err != nil {
+		return fmt.Errorf("error restoring policy %q: %v", result.Name, err)
+	}
+
+	// Restore the original settings instead of deleting the policy.
+	result.Disabled = original.Disabled
+	result.Notifiers = original.Notifiers
+	result.EnforcementActions = original.EnforcementActions
+	result.Whitelists = original.Whitelists
+	logMessage(result)
+
+	empty, resp, err := cli.PolicyServiceApi.PutPolicy(cli.AuthContext(ctx), data.Id(), result)
+	logResult(empty, resp, err)
+
+	return newAPIError(resp, err)
+}
+
+// Import by name.
+func stackRoxDefaultPolicyImportState(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	debug("calling stackRoxDefaultPolicyImportState")
+
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	policy, err := stackRoxFindPolicyByName(ctx, cli, data.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := stackRoxCanonicalPolicyJSON(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Import the resource. The current settings are restored on destroy.
+	data.SetId(policy.Id)
+	if err := data.Set("original_json", original); err != nil {
+		return nil, err
+	}
+	if err := stackRoxDefaultPolicySetState(data, policy); err != nil {
+		return nil, fmt.Errorf("error importing resource: %v", err)
+	}
+
+	return []*schema.ResourceData{data}, nil
+}
+
+// stackRoxFindPolicyByName returns the policy with exactly the given name.
+func stackRoxFindPolicyByName(ctx context.Context, cli ClientWrap, name string) (stackrox.StoragePolicy, error) {
+	result, resp, err := cli.PolicyServiceApi.ListPolicies(
+		cli.AuthContext(ctx),
+		&stackrox.ListPoliciesOpts{
+			// Quoted values are matched exactly, even if they contain separators like `,`, `+` or `:`.
+			Query: optional.NewString(fmt.Sprintf(`Policy:"%s"`, name)),
+		},
+	)
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
+	if err != nil {
+		return stackrox.StoragePolicy{}, err
+	}
+
+	// Look for the exact name in case Central still matches more than one policy.
+	var ids []string
+	for _, policy := range result.Policies {
+		if policy.Name == name {
+			ids = append(ids, policy.Id)
+		}
+	}
+
+	if len(ids) != 1 {
+		return stackrox.StoragePolicy{}, fmt.Errorf("expected one policy named %q, found %d", name, len(ids))
+	}
+
+	policy, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(ctx), ids[0])
+	logResult(policy, resp, err)
+
+	return policy, newAPIError(resp, err)
+}
+
+// stackRoxDefaultPolicyApply updates the settings of the policy.
+func stackRoxDefaultPolicyApply(ctx context.Context, cli ClientWrap, data *schema.ResourceData, policy stackrox.StoragePolicy) error {
+	if disabled, ok := data.GetOkExists("disabled"); ok {
+		policy.Disabled = disabled.(bool)
+	}
+
+	// The original notifiers, enforcement actions and exclusions are kept in `original_json`, so they're replaced
+	// even if they aren't configured.
+	policy.Notifiers = notifiersFrom(data.Get("notifiers").(*schema.Set).List())
+
+	enforcementActions, err := enforcementActionsFrom(data.Get("enforcement_actions").(*schema.Set).List())
+	if err != nil {
+		return err
+	}
+
+	// The lifecycle stages of the policy can't be changed, so the actions must match them.
+	for _, action := range enforcementActions {
+		if !stackRoxContainsLifecycleStage(policy.LifecycleStages, enforcementActionsMap[action]) {
+			return fmt.Errorf("enforcement action %s requires the %s lifecycle stage, which policy %q doesn't have",
+				action, enforcementActionsMap[action], policy.Name)
+		}
+	}
+	policy.EnforcementActions = enforcementActions
+
+	whitelists, err := stackRoxExclusionsFromTerraform(data.Get("exclusion").([]interface{}), time.Now())
+	if err != nil {
+		return err
+	}
+	policy.Whitelists = whitelists
+
+	logMessage(policy)
+
+	result, resp, err := cli.PolicyServiceApi.PutPolicy(cli.AuthContext(ctx), policy.Id, policy)
+	logResult(result, resp, err)
+
+	return newAPIError(resp, err)
+}
+
+func stackRoxDefaultPolicySetState(data *schema.ResourceData, src stackrox.StoragePolicy) error {
+	if err := data.Set("name", src.Name); err != nil {
+		return err
+	}
+	if err := data.Set("disabled", src.Disabled); err != nil {
+		return err
+	}
+	if err := data.Set("notifiers", src.Notifiers); err != nil {
+		return err
+	}
+	if err := data.Set("enforcement_actions", src.EnforcementActions); err != nil {
+		return err
+	}
+
+	prior := data.Get("exclusion").([]interface{})
+	if err := data.Set("exclusion", stackRoxTerraformExclusionsFromMessage(src.Whitelists, prior, time.Now())); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func stackRoxContainsLifecycleStage(stages []stackrox.StorageLifecycleStage, stage stackrox.StorageLifecycleStage) bool {
+	for _, s := range stages {
+		if s == stage {
+			return true
+		}
+	}
+
+	return false
+}
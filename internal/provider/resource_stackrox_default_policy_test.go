@@ -0,0 +1,289 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// testPolicyServer serves a policy `Latest tag` and a policy whose name has it as a prefix.
+type testPolicyServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	policy stackrox.StoragePolicy
+	puts   int
+}
+
+func newTestPolicyServer(t *testing.T) *testPolicyServer {
+	s := &testPolicyServer{
+		policy: stackrox.StoragePolicy{
+			Id:                 "2e90874a-3521-44de-85c6-5720f519a701",
+			Name:               "Latest tag",
+			Categories:         []string{"DevOps Best Practices"},
+			LifecycleStages:    []stackrox.StorageLifecycleStage{"BUILD", "DEPLOY"},
+			Severity:           "LOW_SEVERITY",
+			EnforcementActions: []stackrox.StorageEnforcementAction{},
+			Whitelists: []stackrox.StorageWhitelist{
+				{Name: "kube-system", Deployment: &stackrox.StorageWhitelistDeployment{
+					Scope: &stackrox.StorageScope{Namespace: "kube-system"},
+				}},
+			},
+		},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/policies":
+			assert.Equal(t, `Policy:"Latest tag"`, r.URL.Query().Get("query"))
+			_ = json.NewEncoder(w).Encode(stackrox.V1ListPoliciesResponse{
+				Policies: []stackrox.StorageListPolicy{
+					{Id: "7e0b5f4c-1a8f-4e4b-9a55-4f1fb2a5b8c3", Name: "Latest tag (custom)"},
+					{Id: s.policy.Id, Name: s.policy.Name},
+				},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/policies/"+s.policy.Id:
+			_ = json.NewEncoder(w).Encode(s.policy)
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/policies/"+s.policy.Id:
+			var policy stackrox.StoragePolicy
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&policy))
+			s.policy = policy
+			s.puts++
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found","code":5,"message":"not found"}`))
+		}
+	}))
+
+	return s
+}
+
+func TestStackRoxDefaultPolicy_adoptAndRestore(t *testing.T) {
+	t.Parallel()
+
+	server := newTestPolicyServer(t)
+	defer server.Close()
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+
+	data := schema.TestResourceDataRaw(t, resourceStackRoxDefaultPolicy().Schema, map[string]interface{}{
+		"name":                "Latest tag",
+		"disabled":            true,
+		"notifiers":           []interface{}{"notifier-id"},
+		"enforcement_actions": []interface{}{"FAIL_BUILD_ENFORCEMENT"},
+	})
+
+	require.NoError(t, resourceStackRoxDefaultPolicy().Create(data, cli))
+	assert.Equal(t, "2e90874a-3521-44de-85c6-5720f519a701", data.Id())
+	assert.True(t, server.policy.Disabled)
+	assert.Equal(t, []string{"notifier-id"}, server.policy.Notifiers)
+	assert.Equal(t, []stackrox.StorageEnforcementAction{"FAIL_BUILD_ENFORCEMENT"}, server.policy.EnforcementActions)
+
+	// The exclusions aren't configured, so they're removed.
+	assert.Empty(t, server.policy.Whitelists)
+	assert.Equal(t, 0, data.Get("exclusion.#"))
+
+	require.NoError(t, resourceStackRoxDefaultPolicy().Delete(data, cli))
+	assert.Equal(t, 2, server.puts)
+	assert.False(t, server.policy.Disabled)
+	assert.Empty(t, server.policy.Notifiers)
+	assert.Empty(t, server.policy.EnforcementActions)
+	assert.Len(t, server.policy.Whitelists, 1)
+	assert.Equal(t, "Latest tag", server.policy.Name)
+}
+
+func TestStackRoxDefaultPolicy_clearEnforcementActions(t *testing.T) {
+	t.Parallel()
+
+	server := newTestPolicyServer(t)
+	defer server.Close()
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+
+	data := schema.TestResourceDataRaw(t, resourceStackRoxDefaultPolicy().Schema, map[string]interface{}{
+		"name":                "Latest tag",
+		"enforcement_actions": []interface{}{"FAIL_BUILD_ENFORCEMENT"},
+	})
+	require.NoError(t, resourceStackRoxDefaultPolicy().Create(data, cli))
+	assert.Equal(t, []stackrox.StorageEnforcementAction{"FAIL_BUILD_ENFORCEMENT"}, server.policy.EnforcementActions)
+
+	// Remove the enforcement actions from the configuration.
+	data = schema.TestResourceDataRaw(t, resourceStackRoxDefaultPolicy().Schema, map[string]interface{}{
+		"name": "Latest tag",
+	})
+	require.NoError(t, stackRoxDefaultPolicyApply(context.Background(), cli, data, server.policy))
+	assert.Empty(t, server.policy.EnforcementActions)
+	assert.Equal(t, 2, server.puts)
+}
+
+func TestStackRoxDefaultPolicy_enforcementRequiresLifecycleStage(t *testing.T) {
+	t.Parallel()
+
+	server := newTestPolicyServer(t)
+	defer server.Close()
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+
+	data := schema.TestResourceDataRaw(t, resourceStackRoxDefaultPolicy().Schema, map[string]interface{}{
+		"name":                "Latest tag",
+		"enforcement_actions": []interface{}{"KILL_POD_ENFORCEMENT"},
+	})
+
+	err := resourceStackRoxDefaultPolicy().Create(data, cli)
+	assert.EqualError(t, err, `enforcement action KILL_POD_ENFORCEMENT requires the RUNTIME lifecycle stage, which policy "Latest tag" doesn't have`)
+	assert.Equal(t, 0, server.puts)
+}
+
+func TestStackRoxFindPolicyByName(t *testing.T) {
+	t.Parallel()
+
+	server := newTestPolicyServer(t)
+	defer server.Close()
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+
+	policy, err := stackRoxFindPolicyByName(context.Background(), cli, "Latest tag")
+	require.NoError(t, err)
+	assert.Equal(t, "2e90874a-3521-44de-85c6-5720f519a701", policy.Id)
+}
+
+func TestStackRoxFindPolicyByName_separators(t *testing.T) {
+	t.Parallel()
+
+	name := "Secure Shell (ssh) Port Exposed, in Image"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/policies":
+			assert.Equal(t, `Policy:"Secure Shell (ssh) Port Exposed, in Image"`, r.URL.Query().Get("query"))
+			_ = json.NewEncoder(w).Encode(stackrox.V1ListPoliciesResponse{
+				Policies: []stackrox.StorageListPolicy{
+					{Id: "a1b2c3d4-0000-4000-8000-000000000001", Name: name},
+				},
+			})
+		case "/v1/policies/a1b2c3d4-0000-4000-8000-000000000001":
+			_ = json.NewEncoder(w).Encode(stackrox.StoragePolicy{Id: "a1b2c3d4-0000-4000-8000-000000000001", Name: name})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	cli := NewTokenClientWrap(server.URL, "token", newHTTPClient(nil, 0, 0))
+
+	policy, err := stackRoxFindPolicyByName(context.Background(), cli, name)
+	require.NoError(t, err)
+	assert.Equal(t, name, policy.Name)
+}
+
+func TestAccStackRoxDefaultPolicy_basic(t *testing.T) {
+	address := "stackrox_default_policy.latest_tag"
+
+	var policy stackrox.StoragePolicy
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStackRoxDefaultPolicyConfig(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxDefaultPolicyExists(address, &policy),
+					resource.TestCheckResourceAttr(address, "disabled", "true"),
+					resource.TestCheckResourceAttr(address, "enforcement_actions.#", "1"),
+					resource.TestCheckResourceAttrSet(address, "original_json"),
+				),
+			},
+			{
+				Config: testAccStackRoxDefaultPolicyConfig(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxDefaultPolicyExists(address, &policy),
+					resource.TestCheckResourceAttr(address, "disabled", "false"),
+				),
+			},
+		},
+		CheckDestroy: testAccCheckStackRoxDefaultPolicyWasRestored(address),
+	})
+}
+
+func testAccCheckStackRoxDefaultPolicyExists(address string, out *stackrox.StoragePolicy) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		res, ok := state.RootModule().Resources[address]
+		if !ok {
+			return fmt.Errorf("not found: %s", address)
+		}
+
+		cli := testAccClientWrap()
+
+		result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(context.Background()), res.Primary.ID)
+		if err := newAPIError(resp, err); err != nil {
+			return fmt.Errorf("error fetching resource: %v", err)
+		}
+
+		*out = result
+
+		return nil
+	}
+}
+
+func testAccCheckStackRoxDefaultPolicyWasRestored(address string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		res, ok := state.RootModule().Resources[address]
+		if !ok {
+			return fmt.Errorf("not found: %s", address)
+		}
+
+		cli := testAccClientWrap()
+
+		// The default policy must still exist.
+		result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(context.Background()), res.Primary.ID)
+		if err := newAPIError(resp, err); err != nil {
+			return fmt.Errorf("default policy wasn't restored: %v", err)
+		}
+
+		if len(result.EnforcementActions) != 0 {
+			return fmt.Errorf("enforcement actions of default policy weren't restored: %v", result.EnforcementActions)
+		}
+
+		return nil
+	}
+}
+
+func testAccStackRoxDefaultPolicyConfig(disabled bool) string {
+	const config = testAccProviderConfig + `
+resource "stackrox_default_policy" "latest_tag" {
+  name                = "Latest tag"
+  disabled            = %t
+  enforcement_actions = [
+    "FAIL_BUILD_ENFORCEMENT"
+  ]
+}
+`
+	return fmt.Sprintf(config, disabled)
+}
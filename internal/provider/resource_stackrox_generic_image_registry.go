@@ -18,7 +18,7 @@ package provider
 
 import (
 	"fmt"
-	"net/http"
+	"time"
 
 	"github.com/antihax/optional"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -33,6 +33,12 @@ func resourceStackRoxGenericImageRegistry() *schema.Resource {
 		Update:   stackRoxGenericImageRegistryUpdate,
 		Delete:   stackRoxGenericImageRegistryDelete,
 		Importer: stackRoxGenericImageRegistryImporter(),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -59,6 +65,10 @@ func resourceStackRoxGenericImageRegistry() *schema.Resource {
 func stackRoxGenericImageRegistryCreate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxGenericImageRegistryCreate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
 	message := stackrox.StorageImageIntegration{
 		Name:       data.Get("name").(string),
 		Categories: []stackrox.StorageImageIntegrationCategory{stackrox.STORAGEIMAGEINTEGRATIONCATEGORY_REGISTRY},
@@ -74,9 +84,9 @@ func stackRoxGenericImageRegistryCreate(data *schema.ResourceData, meta interfac
 
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.ImageIntegrationServiceApi.PostImageIntegration(cli.BasicAuthContext(), message)
+	result, resp, err := cli.ImageIntegrationServiceApi.PostImageIntegration(cli.AuthContext(ctx), message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return err
 	}
@@ -88,25 +98,26 @@ func stackRoxGenericImageRegistryCreate(data *schema.ResourceData, meta interfac
 func stackRoxGenericImageRegistryRead(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxGenericImageRegistryRead")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	result, resp, err := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.BasicAuthContext(), data.Id())
-	logResult(result, resp, err)
-	if err != nil {
-		return err
-	}
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf(resp.Status)
-	}
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.AuthContext(ctx), data.Id())
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// If the resource does not exist, inform Terraform. We want to immediately
 	// return here to prevent further processing.
-	if resp.StatusCode == http.StatusNotFound {
+	if isNotFound(err) {
 		data.SetId("")
 		return nil
 	}
 
+	if err != nil {
+		return err
+	}
+
 	// Update the local state.
 	stackRoxGenericImageRegistrySetState(data, result)
 	return nil
@@ -121,22 +132,22 @@ func stackRoxGenericImageRegistryUpdate(data *schema.ResourceData, meta interfac
 func stackRoxGenericImageRegistryDelete(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxGenericImageRegistryDelete: " + data.Id())
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
 	// Attempt to delete from an upstream API.
 	// data.SetId("") is automatically called assuming delete returns no errors.
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.ImageIntegrationServiceApi.DeleteImageIntegration(cli.BasicAuthContext(), data.Id())
+	result, resp, err := cli.ImageIntegrationServiceApi.DeleteImageIntegration(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// Destroy should be idempotent. The cluster API returns 404 when the resource isn't found.
-	if resp != nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound) {
-		return nil
-	}
-
-	if err != nil {
+	if err != nil && !isNotFound(err) {
 		return err
 	}
 
-	return fmt.Errorf(resp.Status)
+	return nil
 }
 
 func stackRoxGenericImageRegistryImporter() *schema.ResourceImporter {
@@ -149,23 +160,23 @@ func stackRoxGenericImageRegistryImporter() *schema.ResourceImporter {
 func stackRoxGenericImageRegistryImportState(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	debug("calling stackRoxGenericImageRegistryImportState")
 
-	// Attempt to read from an upstream API, using the name as a natural key.
 	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API, using the name as a natural key.
 	result, resp, err := cli.ImageIntegrationServiceApi.GetImageIntegrations(
-		cli.BasicAuthContext(),
+		cli.AuthContext(ctx),
 		&stackrox.GetImageIntegrationsOpts{
 			Name: optional.NewString(data.Id()),
 		},
 	)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(resp.Status)
-	}
-
 	if len(result.Integrations) != 1 {
 		return nil, fmt.Errorf("invalid number of integrations: %d", len(result.Integrations))
 	}
@@ -17,6 +17,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -38,6 +39,7 @@ func TestAccStackRoxGenericImageRegistry_basic(t *testing.T) {
 	var registry stackrox.StorageImageIntegration
 
 	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
 		Providers: testAccProviders(),
 		Steps: []resource.TestStep{
 			// Exercise the plan, apply, refresh, and destroy life cycles.
@@ -66,6 +68,7 @@ func TestAccStackRoxGenericImageRegistry_basic(t *testing.T) {
 
 func TestAccStackRoxGenericImageRegistry_destroyIsIdempotent(t *testing.T) {
 	t.Parallel()
+	testAccPreCheck(t)
 
 	id := acctest.RandString(10)
 	data := &schema.ResourceData{}
@@ -102,7 +105,7 @@ func testAccCheckStackRoxGenericImageRegistryExists(resourceName string, out *st
 
 		cli := testAccClientWrap()
 
-		result, resp, err := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.BasicAuthContext(), res.Primary.ID)
+		result, resp, err := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		*out = result
 
@@ -128,7 +131,7 @@ resource "stackrox_generic_image_registry" "%s" {
 }
 `
 
-	return fmt.Sprintf(config, testAccEndpoint(), testAccPassword(), resourceName, resourceName)
+	return fmt.Sprintf(config, resourceName, resourceName)
 }
 
 func testAccCheckStackRoxGenericImageRegistryWasDestroyed(resourceName string) resource.TestCheckFunc {
@@ -144,7 +147,7 @@ func testAccCheckStackRoxGenericImageRegistryWasDestroyed(resourceName string) r
 
 		cli := testAccClientWrap()
 
-		_, resp, _ := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.BasicAuthContext(), res.Primary.ID)
+		_, resp, _ := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
 			return fmt.Errorf("remote image-registry resource was not destroyed. status: %v", resp.Status)
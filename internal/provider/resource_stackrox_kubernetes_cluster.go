@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
@@ -34,6 +35,12 @@ func resourceStackRoxKubernetesCluster() *schema.Resource {
 		Update:   stackRoxKubernetesClusterUpdate,
 		Delete:   stackRoxKubernetesClusterDelete,
 		Importer: stackRoxKubernetesClusterImporter(),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -68,6 +75,10 @@ func resourceStackRoxKubernetesCluster() *schema.Resource {
 func stackRoxKubernetesClusterCreate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxKubernetesClusterCreate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
 	clusterID := data.Get("cluster_id").(string)
 
 	message := stackrox.StorageCluster{
@@ -83,9 +94,9 @@ func stackRoxKubernetesClusterCreate(data *schema.ResourceData, meta interface{}
 
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.ClustersServiceApi.PutCluster(cli.BasicAuthContext(), clusterID, message)
+	result, resp, err := cli.ClustersServiceApi.PutCluster(cli.AuthContext(ctx), clusterID, message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return err
 	}
@@ -99,14 +110,18 @@ func stackRoxKubernetesClusterCreate(data *schema.ResourceData, meta interface{}
 func stackRoxKubernetesClusterRead(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxKubernetesClusterRead")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	result, resp, err := cli.ClustersServiceApi.GetCluster(cli.BasicAuthContext(), data.Get("cluster_id").(string))
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.ClustersServiceApi.GetCluster(cli.AuthContext(ctx), data.Get("cluster_id").(string))
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// If the resource does not exist, inform Terraform. We want to immediately
 	// return here to prevent further processing.
-	if resp != nil && resp.StatusCode == http.StatusNotFound {
+	if isNotFound(err) {
 		data.SetId("")
 		return nil
 	}
@@ -126,6 +141,10 @@ func stackRoxKubernetesClusterRead(data *schema.ResourceData, meta interface{})
 func stackRoxKubernetesClusterUpdate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxKubernetesClusterUpdate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
 	if !data.HasChanges("name", "central_api_endpoint", "collection_method", "runtime_support") {
 		return stackRoxKubernetesClusterRead(data, meta)
 	}
@@ -145,9 +164,9 @@ func stackRoxKubernetesClusterUpdate(data *schema.ResourceData, meta interface{}
 
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.ClustersServiceApi.PutCluster(cli.BasicAuthContext(), clusterID, message)
+	result, resp, err := cli.ClustersServiceApi.PutCluster(cli.AuthContext(ctx), clusterID, message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return err
 	}
@@ -158,22 +177,22 @@ func stackRoxKubernetesClusterUpdate(data *schema.ResourceData, meta interface{}
 func stackRoxKubernetesClusterDelete(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxKubernetesClusterDelete: " + data.Id())
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
 	// Attempt to delete from an upstream API.
 	// data.SetId("") is automatically called assuming delete returns no errors.
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.ClustersServiceApi.DeleteCluster(cli.BasicAuthContext(), data.Id())
+	result, resp, err := cli.ClustersServiceApi.DeleteCluster(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// Destroy should be idempotent. The cluster API returns 500 when the resource isn't found.
-	if resp != nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusInternalServerError) {
+	if isNotFound(err) || (resp != nil && resp.StatusCode == http.StatusInternalServerError) {
 		return nil
 	}
 
-	if err != nil {
-		return err
-	}
-
-	return fmt.Errorf(resp.Status)
+	return err
 }
 
 func stackRoxKubernetesClusterImporter() *schema.ResourceImporter {
@@ -185,10 +204,14 @@ func stackRoxKubernetesClusterImporter() *schema.ResourceImporter {
 func stackRoxKubernetesClusterImportState(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	debug("calling stackRoxKubernetesClusterImportState")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	result, resp, err := cli.ClustersServiceApi.GetCluster(cli.BasicAuthContext(), data.Id())
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.ClustersServiceApi.GetCluster(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -41,6 +42,7 @@ func TestAccStackRoxKubernetesCluster_basic(t *testing.T) {
 	var cluster stackrox.V1ClusterResponse
 
 	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
 		Providers: testAccProviders(),
 		Steps: []resource.TestStep{
 			// Exercise the plan, apply, refresh, and destroy life cycles.
@@ -49,7 +51,6 @@ func TestAccStackRoxKubernetesCluster_basic(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					testAccCheckStackRoxClusterExists(resourceName, &cluster),
 					testAccCheckStackRoxClusterResourceAttributes(testAccStackRoxClusterResourceAddress(resourceName), &cluster),
-					testAccCheckStackRoxClustersShouldNotAutoupgrade(),
 				),
 			},
 			// Exercise the import life cycle.
@@ -66,6 +67,7 @@ func TestAccStackRoxKubernetesCluster_basic(t *testing.T) {
 
 func TestAccStackRoxKubernetesCluster_destroyIsIdempotent(t *testing.T) {
 	t.Parallel()
+	testAccPreCheck(t)
 
 	id := acctest.RandString(10)
 	data := &schema.ResourceData{}
@@ -86,23 +88,6 @@ func testAccCheckStackRoxClusterResourceAttributes(resourceName string, cluster
 	}
 }
 
-func testAccCheckStackRoxClustersShouldNotAutoupgrade() resource.TestCheckFunc {
-	return func(state *terraform.State) error {
-		cli := testAccClientWrap()
-
-		result, _, err := cli.SensorUpgradeServiceApi.GetSensorUpgradeConfig(cli.BasicAuthContext())
-		if err != nil {
-			return err
-		}
-
-		if result.Config.EnableAutoUpgrade {
-			return fmt.Errorf("Expected automatically upgrading secured clusters to be disabled!!!")
-		}
-
-		return nil
-	}
-}
-
 func testAccStackRoxClusterConfig(resourceName, clusterID string) string {
 	const config = testAccProviderConfig + `
 resource "stackrox_kubernetes_cluster" "%s" {
@@ -114,7 +99,7 @@ resource "stackrox_kubernetes_cluster" "%s" {
 }
 `
 
-	return fmt.Sprintf(config, testAccEndpoint(), testAccPassword(), resourceName, resourceName, clusterID)
+	return fmt.Sprintf(config, resourceName, resourceName, clusterID)
 }
 
 func testAccStackRoxClusterResourceAddress(resourceName string) string {
@@ -134,7 +119,7 @@ func testAccCheckStackRoxClusterWasDestroyed(resourceName string) resource.TestC
 
 		cli := testAccClientWrap()
 
-		_, resp, _ := cli.ClustersServiceApi.GetCluster(cli.BasicAuthContext(), res.Primary.ID)
+		_, resp, _ := cli.ClustersServiceApi.GetCluster(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
 			return fmt.Errorf("remote cluster resource was not destroyed. status: %v", resp.Status)
@@ -157,7 +142,7 @@ func testAccCheckStackRoxClusterExists(resourceName string, out *stackrox.V1Clus
 
 		cli := testAccClientWrap()
 
-		result, resp, err := cli.ClustersServiceApi.GetCluster(cli.BasicAuthContext(), res.Primary.ID)
+		result, resp, err := cli.ClustersServiceApi.GetCluster(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		*out = result
 
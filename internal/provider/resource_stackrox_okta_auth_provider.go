@@ -18,7 +18,7 @@ package provider
 
 import (
 	"fmt"
-	"net/http"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 
@@ -32,6 +32,12 @@ func resourceStackRoxOktaAuthProvider() *schema.Resource {
 		Update:   stackRoxOktaAuthProviderUpdate,
 		Delete:   stackRoxOktaAuthProviderDelete,
 		Importer: stackRoxOktaAuthProviderImporter(),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -87,6 +93,10 @@ func resourceStackRoxOktaAuthProvider() *schema.Resource {
 func stackRoxOktaAuthProviderCreate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxOktaAuthProviderCreate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
 	data.Partial(true)
 
 	if data.HasChanges("name", "type", "ui_endpoint", "enabled", "idp_metadata_url", "sp_issuer") {
@@ -103,9 +113,9 @@ func stackRoxOktaAuthProviderCreate(data *schema.ResourceData, meta interface{})
 
 		logMessage(message)
 
-		cli := meta.(ClientWrap)
-		result, resp, err := cli.AuthProviderServiceApi.PostAuthProvider(cli.BasicAuthContext(), message)
+		result, resp, err := cli.AuthProviderServiceApi.PostAuthProvider(cli.AuthContext(ctx), message)
 		logResult(result, resp, err)
+		err = newAPIError(resp, err)
 		if err != nil {
 			return err
 		}
@@ -134,9 +144,9 @@ func stackRoxOktaAuthProviderCreate(data *schema.ResourceData, meta interface{})
 	}
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.GroupServiceApi.BatchUpdate(cli.BasicAuthContext(), message)
+	result, resp, err := cli.GroupServiceApi.BatchUpdate(cli.AuthContext(ctx), message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return err
 	}
@@ -149,14 +159,18 @@ func stackRoxOktaAuthProviderCreate(data *schema.ResourceData, meta interface{})
 func stackRoxOktaAuthProviderRead(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxOktaAuthProviderRead")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	authProvider, resp, err := cli.AuthProviderServiceApi.GetAuthProvider(cli.BasicAuthContext(), data.Id())
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	authProvider, resp, err := cli.AuthProviderServiceApi.GetAuthProvider(cli.AuthContext(ctx), data.Id())
 	logResult(authProvider, resp, err)
+	err = newAPIError(resp, err)
 
 	// If the resource does not exist, inform Terraform. We want to immediately
 	// return here to prevent further processing.
-	if resp.StatusCode == http.StatusNotFound {
+	if isNotFound(err) {
 		data.SetId("")
 		return nil
 	}
@@ -165,8 +179,9 @@ func stackRoxOktaAuthProviderRead(data *schema.ResourceData, meta interface{}) e
 		return err
 	}
 
-	groups, resp, err := cli.FindGroups(cli.BasicAuthContext(), data.Id())
+	groups, resp, err := cli.FindGroups(cli.AuthContext(ctx), data.Id())
 	logResult(authProvider, resp, err)
+	err = newAPIError(resp, err)
 
 	if err != nil {
 		return err
@@ -214,6 +229,10 @@ func stackRoxOktaAuthProviderSetState(data *schema.ResourceData, provider stackr
 func stackRoxOktaAuthProviderUpdate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxOktaAuthProviderUpdate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
 	data.Partial(true)
 
 	if data.HasChanges("name", "type", "ui_endpoint", "enabled", "idp_metadata_url", "sp_issuer") {
@@ -230,9 +249,9 @@ func stackRoxOktaAuthProviderUpdate(data *schema.ResourceData, meta interface{})
 
 		logMessage(message)
 
-		cli := meta.(ClientWrap)
-		result, resp, err := cli.AuthProviderServiceApi.PutAuthProvider(cli.BasicAuthContext(), data.Id(), message)
+		result, resp, err := cli.AuthProviderServiceApi.PutAuthProvider(cli.AuthContext(ctx), data.Id(), message)
 		logResult(result, resp, err)
+		err = newAPIError(resp, err)
 		if err != nil {
 			return err
 		}
@@ -261,9 +280,9 @@ func stackRoxOktaAuthProviderUpdate(data *schema.ResourceData, meta interface{})
 	}
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.GroupServiceApi.BatchUpdate(cli.BasicAuthContext(), message)
+	result, resp, err := cli.GroupServiceApi.BatchUpdate(cli.AuthContext(ctx), message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return err
 	}
@@ -276,10 +295,14 @@ func stackRoxOktaAuthProviderUpdate(data *schema.ResourceData, meta interface{})
 func stackRoxOktaAuthProviderDelete(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxOktaAuthProviderDelete: " + data.Id())
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	result, resp, err := cli.AuthProviderServiceApi.DeleteAuthProvider(cli.BasicAuthContext(), data.Id())
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.AuthProviderServiceApi.DeleteAuthProvider(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	return err
 }
@@ -293,14 +316,18 @@ func stackRoxOktaAuthProviderImporter() *schema.ResourceImporter {
 func stackRoxOktaAuthProviderImportState(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	debug("calling stackRoxOktaAuthProviderRead")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	authProvider, resp, err := cli.AuthProviderServiceApi.GetAuthProvider(cli.BasicAuthContext(), data.Id())
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	authProvider, resp, err := cli.AuthProviderServiceApi.GetAuthProvider(cli.AuthContext(ctx), data.Id())
 	logResult(authProvider, resp, err)
+	err = newAPIError(resp, err)
 
 	// If the resource does not exist, inform Terraform. We want to immediately
 	// return here to prevent further processing.
-	if resp != nil && resp.StatusCode == http.StatusNotFound {
+	if isNotFound(err) {
 		data.SetId("")
 		return nil, nil
 	}
@@ -309,8 +336,9 @@ func stackRoxOktaAuthProviderImportState(data *schema.ResourceData, meta interfa
 		return nil, err
 	}
 
-	groups, resp, err := cli.FindGroups(cli.BasicAuthContext(), data.Id())
+	groups, resp, err := cli.FindGroups(cli.AuthContext(ctx), data.Id())
 	logResult(authProvider, resp, err)
+	err = newAPIError(resp, err)
 
 	if err != nil {
 		return nil, err
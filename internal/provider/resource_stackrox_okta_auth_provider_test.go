@@ -17,6 +17,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -41,6 +42,7 @@ func TestAccStackRoxOktaAuthProvider_basic(t *testing.T) {
 	var groups []stackrox.StorageGroup
 
 	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
 		Providers: testAccProviders(),
 		Steps: []resource.TestStep{
 			// Exercise the plan, apply, refresh, and destroy life cycles.
@@ -65,6 +67,7 @@ func TestAccStackRoxOktaAuthProvider_basic(t *testing.T) {
 
 func TestAccStackRoxOktaAuthProvider_destroyIsIdempotent(t *testing.T) {
 	t.Parallel()
+	testAccPreCheck(t)
 
 	id := acctest.RandString(10)
 	data := &schema.ResourceData{}
@@ -96,8 +99,6 @@ resource "stackrox_okta_auth_provider" "%s" {
 }
 `
 	return fmt.Sprintf(config,
-		testAccEndpoint(),
-		testAccPassword(),
 		resourceName,
 		resourceName,
 	)
@@ -116,7 +117,7 @@ func testAccCheckStackRoxOktaAuthProviderExists(resourceName string, outAuthProv
 
 		cli := testAccClientWrap()
 
-		result, resp, err := cli.AuthProviderServiceApi.GetAuthProvider(cli.BasicAuthContext(), res.Primary.ID)
+		result, resp, err := cli.AuthProviderServiceApi.GetAuthProvider(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		*outAuthProvider = result
 
@@ -128,7 +129,7 @@ func testAccCheckStackRoxOktaAuthProviderExists(resourceName string, outAuthProv
 			return fmt.Errorf("status is not OK: %s", resp.Status)
 		}
 
-		groups, resp, err := cli.FindGroups(cli.BasicAuthContext(), res.Primary.ID)
+		groups, resp, err := cli.FindGroups(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		if err != nil {
 			return fmt.Errorf("error fetching groups: %v", err)
@@ -182,7 +183,7 @@ func testAccCheckStackRoxOktaAuthProviderWasDestroyed(resourceName string) resou
 
 		cli := testAccClientWrap()
 
-		_, resp, _ := cli.AuthProviderServiceApi.GetAuthProvider(cli.BasicAuthContext(), res.Primary.ID)
+		_, resp, _ := cli.AuthProviderServiceApi.GetAuthProvider(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
 			return fmt.Errorf("remote okta auth provider resource was not destroyed. status: %v", resp.Status)
@@ -18,11 +18,13 @@ package provider
 
 import (
 	"fmt"
-	"log"
-	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 
 	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
 )
@@ -34,68 +36,117 @@ func resourceStackRoxPolicy() *schema.Resource {
 		Update:   stackRoxPolicyUpdate,
 		Delete:   stackRoxPolicyDelete,
 		Importer: stackRoxPolicyImporter(),
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"description": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"rationale": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"remediation": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"disabled": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-			},
-			"categories": {
-				Type:     schema.TypeSet,
-				Required: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
-				Set:      schema.HashString,
-			},
-			"lifecycle_stages": {
-				Type:     schema.TypeSet,
-				Required: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
-				Set:      schema.HashString,
-			},
-			"severity": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"notifiers": {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
-				Set:      schema.HashString,
+		CustomizeDiff: customdiff.All(
+			stackRoxPolicyValidateRequired,
+			stackRoxPolicyValidateLifecycleStages,
+		),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: stackRoxPolicySchema(),
+	}
+}
+
+// stackRoxPolicySchema is the schema of `stackrox_policy`. A policy is given either as structured attributes or as
+// `policy_json`.
+func stackRoxPolicySchema() map[string]*schema.Schema {
+	result := stackRoxPolicyAttributesSchema()
+	for _, attribute := range result {
+		attribute.ConflictsWith = []string{"policy_json"}
+	}
+
+	result["policy_criteria"].ConflictsWith = nil
+	result["policy_criteria"].ExactlyOneOf = []string{"policy_criteria", "policy_json"}
+
+	result["policy_json"] = &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		ExactlyOneOf:     []string{"policy_criteria", "policy_json"},
+		ValidateFunc:     validateStackRoxPolicyJSON,
+		DiffSuppressFunc: stackRoxSuppressEquivalentPolicyJSON,
+	}
+	result["canonical_json"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return result
+}
+
+// stackRoxPolicyAttributesSchema returns the structured attributes of a policy, which are shared by
+// `stackrox_policy` and `stackrox_policy_document`, and expanded by stackRoxPolicyMessageFromAttributes.
+func stackRoxPolicyAttributesSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"rationale": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"remediation": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"disabled": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"categories": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+			Set:      schema.HashString,
+		},
+		"lifecycle_stages": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(lifecycleStageNames(), false),
 			},
-			"policy_criteria": {
-				Type:     schema.TypeList,
-				MaxItems: 1,
-				Required: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"cvss": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						"privileged": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-					},
-				},
+			Set: schema.HashString,
+		},
+		"severity": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice(severityNames(), false),
+		},
+		"notifiers": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+			Set:      schema.HashString,
+		},
+		"enforcement_actions": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(enforcementActionNames(), false),
 			},
+			Set: schema.HashString,
+		},
+		"scope": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     stackRoxScopeResource(),
+		},
+		"exclusion": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     stackRoxExclusionResource(),
 		},
+		"policy_criteria": stackRoxPolicyCriteriaSchema(),
 	}
 }
 
@@ -106,6 +157,15 @@ var severityMap = map[string]stackrox.StorageSeverity{
 	"CRITICAL_SEVERITY": stackrox.STORAGESEVERITY_CRITICAL_SEVERITY,
 }
 
+func severityNames() []string {
+	result := make([]string, 0, len(severityMap))
+	for name := range severityMap {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
 func severityFrom(s string) (stackrox.StorageSeverity, error) {
 	result, ok := severityMap[s]
 	if !ok {
@@ -120,6 +180,15 @@ var lifecycleStagesMap = map[string]stackrox.StorageLifecycleStage{
 	"RUNTIME": stackrox.STORAGELIFECYCLESTAGE_RUNTIME,
 }
 
+func lifecycleStageNames() []string {
+	result := make([]string, 0, len(lifecycleStagesMap))
+	for name := range lifecycleStagesMap {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
 func categoriesFrom(l []interface{}) ([]string, error) {
 	result := make([]string, 0, len(l))
 
@@ -146,6 +215,37 @@ func lifecycleStagesFrom(l []interface{}) ([]stackrox.StorageLifecycleStage, err
 	return result, nil
 }
 
+// enforcementActionsMap maps the enforcement actions to the lifecycle stage in which they're applied.
+var enforcementActionsMap = map[stackrox.StorageEnforcementAction]stackrox.StorageLifecycleStage{
+	stackrox.STORAGEENFORCEMENTACTION_FAIL_BUILD_ENFORCEMENT:                    stackrox.STORAGELIFECYCLESTAGE_BUILD,
+	stackrox.STORAGEENFORCEMENTACTION_SCALE_TO_ZERO_ENFORCEMENT:                 stackrox.STORAGELIFECYCLESTAGE_DEPLOY,
+	stackrox.STORAGEENFORCEMENTACTION_UNSATISFIABLE_NODE_CONSTRAINT_ENFORCEMENT: stackrox.STORAGELIFECYCLESTAGE_DEPLOY,
+	stackrox.STORAGEENFORCEMENTACTION_KILL_POD_ENFORCEMENT:                      stackrox.STORAGELIFECYCLESTAGE_RUNTIME,
+}
+
+func enforcementActionNames() []string {
+	result := make([]string, 0, len(enforcementActionsMap))
+	for action := range enforcementActionsMap {
+		result = append(result, string(action))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func enforcementActionsFrom(l []interface{}) ([]stackrox.StorageEnforcementAction, error) {
+	result := make([]stackrox.StorageEnforcementAction, 0, len(l))
+
+	for _, e := range l {
+		action := stackrox.StorageEnforcementAction(e.(string))
+		if _, ok := enforcementActionsMap[action]; !ok {
+			return nil, fmt.Errorf("Unsupported enforcement action")
+		}
+		result = append(result, action)
+	}
+
+	return result, nil
+}
+
 func notifiersFrom(l []interface{}) []string {
 	result := make([]string, 0, len(l))
 
@@ -157,18 +257,82 @@ func notifiersFrom(l []interface{}) []string {
 	return result
 }
 
+// stackRoxPolicyRequiredAttributes must be set unless the policy is given as `policy_json`.
+var stackRoxPolicyRequiredAttributes = []string{
+	"name", "description", "rationale", "remediation", "categories", "lifecycle_stages", "severity",
+}
+
+func stackRoxPolicyValidateRequired(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("policy_json") || diff.Get("policy_json").(string) != "" {
+		return nil
+	}
+
+	for _, attribute := range stackRoxPolicyRequiredAttributes {
+		if !diff.NewValueKnown(attribute) {
+			continue
+		}
+		if _, ok := diff.GetOk(attribute); !ok {
+			return fmt.Errorf("%q is required unless `policy_json` is set", attribute)
+		}
+	}
+
+	return nil
+}
+
+// stackRoxPolicyValidateLifecycleStages checks at plan time that the criteria and the enforcement actions apply to
+// the lifecycle stages of the policy.
+func stackRoxPolicyValidateLifecycleStages(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("lifecycle_stages") {
+		return nil
+	}
+	stages := diff.Get("lifecycle_stages").(*schema.Set)
+
+	if _, ok := diff.GetOk("policy_criteria.0.dockerfile_line"); ok && !stages.Contains(string(stackrox.STORAGELIFECYCLESTAGE_BUILD)) {
+		return fmt.Errorf("policy_criteria.0.dockerfile_line requires the BUILD lifecycle stage")
+	}
+
+	for _, criterion := range stackRoxRuntimeCriteria {
+		key := "policy_criteria.0." + criterion
+		if _, ok := diff.GetOk(key); ok && !stages.Contains(string(stackrox.STORAGELIFECYCLESTAGE_RUNTIME)) {
+			return fmt.Errorf("%s requires the RUNTIME lifecycle stage", key)
+		}
+	}
+
+	deployOrRuntime := stages.Contains(string(stackrox.STORAGELIFECYCLESTAGE_DEPLOY)) ||
+		stages.Contains(string(stackrox.STORAGELIFECYCLESTAGE_RUNTIME))
+	for _, criterion := range stackRoxDeploymentCriteria {
+		key := "policy_criteria.0." + criterion
+		if _, ok := diff.GetOk(key); ok && !deployOrRuntime {
+			return fmt.Errorf("%s requires the DEPLOY or RUNTIME lifecycle stage", key)
+		}
+	}
+
+	for _, action := range diff.Get("enforcement_actions").(*schema.Set).List() {
+		stage, ok := enforcementActionsMap[stackrox.StorageEnforcementAction(action.(string))]
+		if ok && !stages.Contains(string(stage)) {
+			return fmt.Errorf("enforcement action %s requires the %s lifecycle stage", action, stage)
+		}
+	}
+
+	return nil
+}
+
 func stackRoxPolicyCreate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxPolicyCreate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
 	message, err := stackRoxPolicyMessageFrom(data)
 	if err != nil {
 		return err
 	}
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.PolicyServiceApi.PostPolicy(cli.BasicAuthContext(), message)
+	result, resp, err := cli.PolicyServiceApi.PostPolicy(cli.AuthContext(ctx), message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	if err != nil {
 		return err
@@ -180,35 +344,35 @@ func stackRoxPolicyCreate(data *schema.ResourceData, meta interface{}) error {
 	return stackRoxPolicyRead(data, meta)
 }
 
-func stackRoxBoolPtrFromTerraform(data interface{}) *bool {
-	if data == nil {
-		return nil
-	}
-
-	value := data.(string)
-	if value == "" {
-		return nil
+// stackRoxBoolPtrFromTerraform parses the criteria that are either unset, "true" or "false".
+func stackRoxBoolPtrFromTerraform(data interface{}) (*bool, error) {
+	value, _ := data.(string)
+	switch value {
+	case "":
+		return nil, nil
+	case "true", "false":
+		boolval := value == "true"
+		return &boolval, nil
+	default:
+		return nil, fmt.Errorf("%q must be \"true\" or \"false\"", value)
 	}
-
-	boolval, err := strconv.ParseBool(value)
-	if err != nil {
-		panic(err)
-	}
-
-	return &boolval
 }
 
 func stackRoxPolicyRead(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxPolicyRead")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.BasicAuthContext(), data.Id())
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// If the resource does not exist, inform Terraform. We want to immediately
 	// return here to prevent further processing.
-	if resp.StatusCode == http.StatusNotFound {
+	if isNotFound(err) {
 		data.SetId("")
 		return nil
 	}
@@ -224,8 +388,12 @@ func stackRoxPolicyRead(data *schema.ResourceData, meta interface{}) error {
 func stackRoxPolicyUpdate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxPolicyUpdate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
 	if !data.HasChanges("name", "description", "rationale", "remediation", "disabled",
-		"categories", "lifecycle_stages", "severity", "notifiers", "policy_criteria") {
+		"categories", "lifecycle_stages", "severity", "notifiers", "enforcement_actions", "scope", "exclusion", "policy_criteria", "policy_json") {
 		return stackRoxPolicyRead(data, meta)
 	}
 
@@ -235,9 +403,9 @@ func stackRoxPolicyUpdate(data *schema.ResourceData, meta interface{}) error {
 	}
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.PolicyServiceApi.PutPolicy(cli.BasicAuthContext(), data.Id(), message)
+	result, resp, err := cli.PolicyServiceApi.PutPolicy(cli.AuthContext(ctx), data.Id(), message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	if err != nil {
 		return err
@@ -249,22 +417,22 @@ func stackRoxPolicyUpdate(data *schema.ResourceData, meta interface{}) error {
 func stackRoxPolicyDelete(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxPolicyDelete: " + data.Id())
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
 	// Attempt to delete from an upstream API.
 	// data.SetId("") is automatically called assuming delete returns no errors.
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.PolicyServiceApi.DeletePolicy(cli.BasicAuthContext(), data.Id())
+	result, resp, err := cli.PolicyServiceApi.DeletePolicy(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// Destroy should be idempotent. The cluster API returns 404 when the resource isn't found.
-	if resp != nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound) {
-		return nil
-	}
-
-	if err != nil {
+	if err != nil && !isNotFound(err) {
 		return err
 	}
 
-	return fmt.Errorf(resp.Status)
+	return nil
 }
 
 func stackRoxPolicyImporter() *schema.ResourceImporter {
@@ -277,18 +445,18 @@ func stackRoxPolicyImporter() *schema.ResourceImporter {
 func stackRoxPolicyImportState(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	debug("calling stackRoxPolicyImportState")
 
-	// Attempt to read from an upstream API, using the name as a natural key.
 	cli := meta.(ClientWrap)
-	result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.BasicAuthContext(), data.Id())
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API, using the name as a natural key.
+	result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(resp.Status)
-	}
-
 	// Import the resource.
 	if err := stackRoxPolicySetState(data, result); err != nil {
 		return nil, fmt.Errorf("error importing resource: %v", err)
@@ -298,6 +466,26 @@ func stackRoxPolicyImportState(data *schema.ResourceData, meta interface{}) ([]*
 }
 
 func stackRoxPolicySetState(data *schema.ResourceData, src stackrox.StoragePolicy) error {
+	canonical, err := stackRoxCanonicalPolicyJSON(src)
+	if err != nil {
+		return err
+	}
+	if err := data.Set("canonical_json", canonical); err != nil {
+		return err
+	}
+
+	// A policy managed as JSON keeps the configured JSON unless it drifted from the policy in Central.
+	if policyJSON := data.Get("policy_json").(string); policyJSON != "" {
+		policy, err := stackRoxPolicyFromJSON(policyJSON)
+		if err == nil {
+			configured, err := stackRoxCanonicalPolicyJSON(policy)
+			if err == nil && configured == canonical {
+				return nil
+			}
+		}
+		return data.Set("policy_json", canonical)
+	}
+
 	if err := data.Set("name", src.Name); err != nil {
 		return err
 	}
@@ -325,15 +513,24 @@ func stackRoxPolicySetState(data *schema.ResourceData, src stackrox.StoragePolic
 	if err := data.Set("notifiers", src.Notifiers); err != nil {
 		return err
 	}
+	if err := data.Set("enforcement_actions", src.EnforcementActions); err != nil {
+		return err
+	}
+	if err := data.Set("scope", stackRoxTerraformScopesFromMessage(src.Scope)); err != nil {
+		return err
+	}
+
+	prior := data.Get("exclusion").([]interface{})
+	if err := data.Set("exclusion", stackRoxTerraformExclusionsFromMessage(src.Whitelists, prior, time.Now())); err != nil {
+		return err
+	}
 
 	// policy_criteria
-	criteria := []map[string]interface{}{
-		{
-			"cvss":       stackRoxTerraformCvssFromMessage(src.Fields.Cvss),
-			"privileged": stackRoxTerraformBoolFromMessage(src.Fields.Privileged),
-		},
+	criteria, err := stackRoxTerraformPolicyCriteriaFromMessage(src.Fields)
+	if err != nil {
+		return err
 	}
-	if err := data.Set("policy_criteria", criteria); err != nil {
+	if err := data.Set("policy_criteria", []interface{}{criteria}); err != nil {
 		return err
 	}
 
@@ -341,6 +538,15 @@ func stackRoxPolicySetState(data *schema.ResourceData, src stackrox.StoragePolic
 }
 
 func stackRoxPolicyMessageFrom(data *schema.ResourceData) (message stackrox.StoragePolicy, err error) {
+	if policyJSON := data.Get("policy_json").(string); policyJSON != "" {
+		return stackRoxPolicyFromJSON(policyJSON)
+	}
+
+	return stackRoxPolicyMessageFromAttributes(data)
+}
+
+// stackRoxPolicyMessageFromAttributes expands the attributes of stackRoxPolicyAttributesSchema.
+func stackRoxPolicyMessageFromAttributes(data *schema.ResourceData) (message stackrox.StoragePolicy, err error) {
 	categories, err := categoriesFrom(data.Get("categories").(*schema.Set).List())
 	if err != nil {
 		return
@@ -351,95 +557,52 @@ func stackRoxPolicyMessageFrom(data *schema.ResourceData) (message stackrox.Stor
 		return
 	}
 
-	severity, err := severityFrom(data.Get("severity").(string))
-	if err != nil {
-		return
+	var severity stackrox.StorageSeverity
+	if s := data.Get("severity").(string); s != "" {
+		severity, err = severityFrom(s)
+		if err != nil {
+			return
+		}
 	}
 
 	notifiers := notifiersFrom(data.Get("notifiers").(*schema.Set).List())
 
-	policyCriteria := data.Get("policy_criteria").([]interface{})
-	criteria := policyCriteria[0].(map[string]interface{})
-	log.Println(criteria)
-
-	message = stackrox.StoragePolicy{
-		Name:            data.Get("name").(string),
-		Description:     data.Get("description").(string),
-		Rationale:       data.Get("rationale").(string),
-		Remediation:     data.Get("remediation").(string),
-		Disabled:        data.Get("disabled").(bool),
-		Categories:      categories,
-		LifecycleStages: lifecycleStages,
-		Severity:        severity,
-		Notifiers:       notifiers,
-		Fields: stackrox.StoragePolicyFields{
-			Cvss:       stackRoxCvssFromTerraform(criteria["cvss"]),
-			Privileged: stackRoxBoolPtrFromTerraform(criteria["privileged"]),
-		},
-	}
-
-	return
-}
-
-var stackRoxCvssSymbolToOp = map[string]stackrox.StorageComparator{
-	"=":  stackrox.STORAGECOMPARATOR_EQUALS,
-	">":  stackrox.STORAGECOMPARATOR_GREATER_THAN,
-	">=": stackrox.STORAGECOMPARATOR_GREATER_THAN_OR_EQUALS,
-	"<":  stackrox.STORAGECOMPARATOR_LESS_THAN,
-	"<=": stackrox.STORAGECOMPARATOR_LESS_THAN_OR_EQUALS,
-}
-
-var stackRoxCvssOpsToSymbols = map[stackrox.StorageComparator]string{
-	stackrox.STORAGECOMPARATOR_EQUALS:                 "=",
-	stackrox.STORAGECOMPARATOR_GREATER_THAN:           ">",
-	stackrox.STORAGECOMPARATOR_GREATER_THAN_OR_EQUALS: ">=",
-	stackrox.STORAGECOMPARATOR_LESS_THAN:              "<",
-	stackrox.STORAGECOMPARATOR_LESS_THAN_OR_EQUALS:    "<=",
-}
-
-func stackRoxTerraformCvssFromMessage(cvss *stackrox.StorageNumericalPolicy) (result string) {
-	if cvss == nil {
+	exclusions, err := stackRoxExclusionsFromTerraform(data.Get("exclusion").([]interface{}), time.Now())
+	if err != nil {
 		return
 	}
 
-	op, err := stackRoxCvssOpFrom(cvss.Op)
+	enforcementActions, err := enforcementActionsFrom(data.Get("enforcement_actions").(*schema.Set).List())
 	if err != nil {
-		panic(err)
-	}
-
-	result = fmt.Sprintf("%s %d", op, int32(cvss.Value))
-
-	return
-}
-
-func stackRoxCvssFromTerraform(data interface{}) *stackrox.StorageNumericalPolicy {
-	if data == nil {
-		return nil
+		return
 	}
 
-	value := data.(string)
-	if value == "" {
-		return nil
-	}
+	var fields stackrox.StoragePolicyFields
+	if criteria := stackRoxSingleBlockFromTerraform(data.Get("policy_criteria")); criteria != nil {
+		debug(criteria)
 
-	var op string
-	var val int32
-	log.Println(value)
-	if n, err := fmt.Sscanf(value, "%s %d", &op, &val); n != 2 || err != nil {
-		panic(err)
+		fields, err = stackRoxPolicyFieldsFromTerraform(criteria)
+		if err != nil {
+			return
+		}
 	}
 
-	return &stackrox.StorageNumericalPolicy{
-		Op:    stackRoxCvssSymbolToOp[op],
-		Value: float32(val),
+	message = stackrox.StoragePolicy{
+		Name:               data.Get("name").(string),
+		Description:        data.Get("description").(string),
+		Rationale:          data.Get("rationale").(string),
+		Remediation:        data.Get("remediation").(string),
+		Disabled:           data.Get("disabled").(bool),
+		Categories:         categories,
+		LifecycleStages:    lifecycleStages,
+		Severity:           severity,
+		Notifiers:          notifiers,
+		EnforcementActions: enforcementActions,
+		Scope:              stackRoxScopesFromTerraform(data.Get("scope").([]interface{})),
+		Whitelists:         exclusions,
+		Fields:             fields,
 	}
-}
 
-func stackRoxCvssOpFrom(op stackrox.StorageComparator) (result string, err error) {
-	result, ok := stackRoxCvssOpsToSymbols[op]
-	if !ok {
-		err = fmt.Errorf("Invalid CVSS comparator")
-	}
 	return
 }
 
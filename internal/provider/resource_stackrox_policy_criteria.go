@@ -0,0 +1,956 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// stackRoxPolicyCriteriaSchema is the schema of the `policy_criteria` block, which maps to `StoragePolicyFields`.
+func stackRoxPolicyCriteriaSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		MaxItems: 1,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cvss": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateStackRoxNumericalPolicy,
+				},
+				"privileged": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateStackRoxBoolString,
+				},
+				"image_name": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"registry": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"remote": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"tag": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+						},
+					},
+				},
+				"image_age_days": {
+					Type:     schema.TypeInt,
+					Optional: true,
+				},
+				"scan_age_days": {
+					Type:     schema.TypeInt,
+					Optional: true,
+				},
+				"no_scan_exists": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateStackRoxBoolString,
+				},
+				"cve": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringIsValidRegExp,
+				},
+				"fixed_by": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringIsValidRegExp,
+				},
+				"component": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"name": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"version": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+						},
+					},
+				},
+				"add_capabilities": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+					Set:      schema.HashString,
+				},
+				"drop_capabilities": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+					Set:      schema.HashString,
+				},
+				"read_only_root_fs": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateStackRoxBoolString,
+				},
+				"volume": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"name": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"source": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"destination": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"type": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"read_only": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validateStackRoxBoolString,
+							},
+						},
+					},
+				},
+				"host_mount": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"read_only": {
+								Type:     schema.TypeBool,
+								Required: true,
+							},
+						},
+					},
+				},
+				"user": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringIsValidRegExp,
+				},
+				"directory": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringIsValidRegExp,
+				},
+				"process": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"name": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"args": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"ancestor": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+							"uid": {
+								Type:     schema.TypeString,
+								Optional: true,
+							},
+						},
+					},
+				},
+				"process_baseline_violation": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"port": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"port": {
+								Type:         schema.TypeInt,
+								Optional:     true,
+								ValidateFunc: validation.IntBetween(0, 65535),
+							},
+							"protocol": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringInSlice([]string{"TCP", "UDP", "SCTP"}, false),
+							},
+						},
+					},
+				},
+				"port_exposure": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validation.StringInSlice(stackRoxPortExposureLevels, false),
+					},
+					Set: schema.HashString,
+				},
+				"container_resources": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"cpu_request": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validateStackRoxNumericalPolicy,
+							},
+							"cpu_limit": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validateStackRoxNumericalPolicy,
+							},
+							"memory_request": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validateStackRoxNumericalPolicy,
+							},
+							"memory_limit": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validateStackRoxNumericalPolicy,
+							},
+						},
+					},
+				},
+				"permission_level": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringInSlice(stackRoxPermissionLevels, false),
+				},
+				"dockerfile_line": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"instruction": {
+								Type:         schema.TypeString,
+								Required:     true,
+								ValidateFunc: validation.StringInSlice(stackRoxDockerfileInstructions, false),
+							},
+							"value": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+							},
+						},
+					},
+				},
+				"required_label":        stackRoxKeyValuePolicySchema(nil),
+				"required_annotation":   stackRoxKeyValuePolicySchema(nil),
+				"disallowed_annotation": stackRoxKeyValuePolicySchema(nil),
+				"env": stackRoxKeyValuePolicySchema(map[string]*schema.Schema{
+					"source": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice(stackRoxEnvVarSources, false),
+					},
+				}),
+			},
+		},
+	}
+}
+
+// stackRoxRuntimeCriteria are the criteria that are only evaluated at runtime.
+var stackRoxRuntimeCriteria = []string{"process", "process_baseline_violation"}
+
+// stackRoxDeploymentCriteria are the criteria of deployments, which can't be evaluated when images are built.
+var stackRoxDeploymentCriteria = []string{
+	"privileged", "add_capabilities", "drop_capabilities", "read_only_root_fs", "volume", "host_mount",
+	"required_label", "required_annotation", "disallowed_annotation", "env",
+	"port", "port_exposure", "container_resources", "permission_level",
+}
+
+func validateStackRoxBoolString(i interface{}, k string) (warnings []string, errors []error) {
+	if _, err := stackRoxBoolPtrFromTerraform(i); err != nil {
+		errors = append(errors, fmt.Errorf("%s: %v", k, err))
+	}
+	return
+}
+
+func validateStackRoxNumericalPolicy(i interface{}, k string) (warnings []string, errors []error) {
+	if _, err := stackRoxNumericalPolicyFromTerraform(i); err != nil {
+		errors = append(errors, fmt.Errorf("%s: %v", k, err))
+	}
+	return
+}
+
+// stackRoxKeyValuePolicySchema is the schema of the criteria that match key/value pairs with regular expressions,
+// e.g. labels. Additional attributes are merged into the block.
+func stackRoxKeyValuePolicySchema(additional map[string]*schema.Schema) *schema.Schema {
+	attributes := map[string]*schema.Schema{
+		"key": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringIsValidRegExp,
+		},
+		"value": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringIsValidRegExp,
+		},
+	}
+	for k, v := range additional {
+		attributes[k] = v
+	}
+
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		MaxItems: 1,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: attributes,
+		},
+	}
+}
+
+// stackRoxEnvVarSources are the sources of environment variables that can be matched by the `env` criterion.
+var stackRoxEnvVarSources = []string{
+	string(stackrox.ENVIRONMENTCONFIGENVVARSOURCE_RAW),
+	string(stackrox.ENVIRONMENTCONFIGENVVARSOURCE_SECRET_KEY),
+	string(stackrox.ENVIRONMENTCONFIGENVVARSOURCE_CONFIG_MAP_KEY),
+	string(stackrox.ENVIRONMENTCONFIGENVVARSOURCE_FIELD),
+	string(stackrox.ENVIRONMENTCONFIGENVVARSOURCE_RESOURCE_FIELD),
+}
+
+// stackRoxPortExposureLevels are the exposure levels that can be matched by the `port_exposure` criterion.
+var stackRoxPortExposureLevels = []string{
+	string(stackrox.PORTCONFIGEXPOSURELEVEL_EXTERNAL),
+	string(stackrox.PORTCONFIGEXPOSURELEVEL_NODE),
+	string(stackrox.PORTCONFIGEXPOSURELEVEL_INTERNAL),
+	string(stackrox.PORTCONFIGEXPOSURELEVEL_HOST),
+}
+
+// stackRoxPermissionLevels are the RBAC permission levels that can be matched by the `permission_level` criterion.
+var stackRoxPermissionLevels = []string{
+	string(stackrox.STORAGEPERMISSIONLEVEL_NONE),
+	string(stackrox.STORAGEPERMISSIONLEVEL_DEFAULT),
+	string(stackrox.STORAGEPERMISSIONLEVEL_ELEVATED_IN_NAMESPACE),
+	string(stackrox.STORAGEPERMISSIONLEVEL_ELEVATED_CLUSTER_WIDE),
+	string(stackrox.STORAGEPERMISSIONLEVEL_CLUSTER_ADMIN),
+}
+
+// stackRoxDockerfileInstructions are the instructions that can be matched by the `dockerfile_line` criterion.
+var stackRoxDockerfileInstructions = []string{
+	"ADD", "ARG", "CMD", "COPY", "ENTRYPOINT", "ENV", "EXPOSE", "FROM", "HEALTHCHECK", "LABEL", "MAINTAINER",
+	"ONBUILD", "RUN", "SHELL", "STOPSIGNAL", "USER", "VOLUME", "WORKDIR",
+}
+
+// stackRoxPolicyFieldsFromTerraform expands the `policy_criteria` block.
+func stackRoxPolicyFieldsFromTerraform(criteria map[string]interface{}) (stackrox.StoragePolicyFields, error) {
+	cvss, err := stackRoxNumericalPolicyFromTerraform(criteria["cvss"])
+	if err != nil {
+		return stackrox.StoragePolicyFields{}, fmt.Errorf("invalid cvss: %v", err)
+	}
+
+	containerResources, err := stackRoxContainerResourcesFromTerraform(criteria["container_resources"])
+	if err != nil {
+		return stackrox.StoragePolicyFields{}, err
+	}
+
+	privileged, err := stackRoxBoolPtrFromTerraform(criteria["privileged"])
+	if err != nil {
+		return stackrox.StoragePolicyFields{}, fmt.Errorf("invalid privileged: %v", err)
+	}
+
+	noScanExists, err := stackRoxBoolPtrFromTerraform(criteria["no_scan_exists"])
+	if err != nil {
+		return stackrox.StoragePolicyFields{}, fmt.Errorf("invalid no_scan_exists: %v", err)
+	}
+
+	readOnlyRootFs, err := stackRoxBoolPtrFromTerraform(criteria["read_only_root_fs"])
+	if err != nil {
+		return stackrox.StoragePolicyFields{}, fmt.Errorf("invalid read_only_root_fs: %v", err)
+	}
+
+	volume, err := stackRoxVolumeFromTerraform(criteria["volume"])
+	if err != nil {
+		return stackrox.StoragePolicyFields{}, err
+	}
+
+	fields := stackrox.StoragePolicyFields{
+		Cvss:         cvss,
+		Privileged:   privileged,
+		ImageName:    stackRoxImageNameFromTerraform(criteria["image_name"]),
+		ImageAgeDays: stackRoxDaysFromTerraform(criteria["image_age_days"]),
+		ScanAgeDays:  stackRoxDaysFromTerraform(criteria["scan_age_days"]),
+		NoScanExists: noScanExists,
+		Cve:          criteria["cve"].(string),
+		FixedBy:      criteria["fixed_by"].(string),
+		Component:    stackRoxComponentFromTerraform(criteria["component"]),
+
+		AddCapabilities:  stackRoxStringsFromTerraform(criteria["add_capabilities"]),
+		DropCapabilities: stackRoxStringsFromTerraform(criteria["drop_capabilities"]),
+		ReadOnlyRootFs:   readOnlyRootFs,
+		VolumePolicy:     volume,
+		HostMountPolicy:  stackRoxHostMountFromTerraform(criteria["host_mount"]),
+		User:             criteria["user"].(string),
+		Directory:        criteria["directory"].(string),
+
+		ProcessPolicy:    stackRoxProcessFromTerraform(criteria["process"]),
+		WhitelistEnabled: criteria["process_baseline_violation"].(bool),
+
+		RequiredLabel:        stackRoxKeyValueFromTerraform(criteria["required_label"]),
+		RequiredAnnotation:   stackRoxKeyValueFromTerraform(criteria["required_annotation"]),
+		DisallowedAnnotation: stackRoxKeyValueFromTerraform(criteria["disallowed_annotation"]),
+		Env:                  stackRoxKeyValueFromTerraform(criteria["env"]),
+
+		PortPolicy:              stackRoxPortFromTerraform(criteria["port"]),
+		PortExposurePolicy:      stackRoxPortExposureFromTerraform(criteria["port_exposure"]),
+		ContainerResourcePolicy: containerResources,
+		PermissionPolicy:        stackRoxPermissionFromTerraform(criteria["permission_level"]),
+
+		LineRule: stackRoxDockerfileLineFromTerraform(criteria["dockerfile_line"]),
+	}
+
+	return fields, nil
+}
+
+// stackRoxTerraformPolicyCriteriaFromMessage flattens the fields of a policy into the `policy_criteria` block.
+func stackRoxTerraformPolicyCriteriaFromMessage(fields stackrox.StoragePolicyFields) (map[string]interface{}, error) {
+	imageAgeDays, err := stackRoxTerraformDaysFromMessage(fields.ImageAgeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	scanAgeDays, err := stackRoxTerraformDaysFromMessage(fields.ScanAgeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	cvss, err := stackRoxTerraformNumericalPolicyFromMessage(fields.Cvss)
+	if err != nil {
+		return nil, err
+	}
+
+	containerResources, err := stackRoxTerraformContainerResourcesFromMessage(fields.ContainerResourcePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"cvss":           cvss,
+		"privileged":     stackRoxTerraformBoolFromMessage(fields.Privileged),
+		"image_name":     stackRoxTerraformImageNameFromMessage(fields.ImageName),
+		"image_age_days": imageAgeDays,
+		"scan_age_days":  scanAgeDays,
+		"no_scan_exists": stackRoxTerraformBoolFromMessage(fields.NoScanExists),
+		"cve":            fields.Cve,
+		"fixed_by":       fields.FixedBy,
+		"component":      stackRoxTerraformComponentFromMessage(fields.Component),
+
+		"add_capabilities":  fields.AddCapabilities,
+		"drop_capabilities": fields.DropCapabilities,
+		"read_only_root_fs": stackRoxTerraformBoolFromMessage(fields.ReadOnlyRootFs),
+		"volume":            stackRoxTerraformVolumeFromMessage(fields.VolumePolicy),
+		"host_mount":        stackRoxTerraformHostMountFromMessage(fields.HostMountPolicy),
+		"user":              fields.User,
+		"directory":         fields.Directory,
+
+		"process":                    stackRoxTerraformProcessFromMessage(fields.ProcessPolicy),
+		"process_baseline_violation": fields.WhitelistEnabled,
+
+		"required_label":        stackRoxTerraformKeyValueFromMessage(fields.RequiredLabel, false),
+		"required_annotation":   stackRoxTerraformKeyValueFromMessage(fields.RequiredAnnotation, false),
+		"disallowed_annotation": stackRoxTerraformKeyValueFromMessage(fields.DisallowedAnnotation, false),
+		"env":                   stackRoxTerraformKeyValueFromMessage(fields.Env, true),
+
+		"port":                stackRoxTerraformPortFromMessage(fields.PortPolicy),
+		"port_exposure":       stackRoxTerraformPortExposureFromMessage(fields.PortExposurePolicy),
+		"container_resources": containerResources,
+		"permission_level":    stackRoxTerraformPermissionFromMessage(fields.PermissionPolicy),
+
+		"dockerfile_line": stackRoxTerraformDockerfileLineFromMessage(fields.LineRule),
+	}, nil
+}
+
+// stackRoxSingleBlockFromTerraform returns the only element of a block with `MaxItems: 1`, or nil if it isn't set.
+func stackRoxSingleBlockFromTerraform(data interface{}) map[string]interface{} {
+	blocks, ok := data.([]interface{})
+	if !ok || len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+
+	return blocks[0].(map[string]interface{})
+}
+
+func stackRoxImageNameFromTerraform(data interface{}) *stackrox.StorageImageNamePolicy {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil
+	}
+
+	return &stackrox.StorageImageNamePolicy{
+		Registry: block["registry"].(string),
+		Remote:   block["remote"].(string),
+		Tag:      block["tag"].(string),
+	}
+}
+
+func stackRoxTerraformImageNameFromMessage(imageName *stackrox.StorageImageNamePolicy) []interface{} {
+	if imageName == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"registry": imageName.Registry,
+			"remote":   imageName.Remote,
+			"tag":      imageName.Tag,
+		},
+	}
+}
+
+func stackRoxComponentFromTerraform(data interface{}) *stackrox.StorageComponent {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil
+	}
+
+	return &stackrox.StorageComponent{
+		Name:    block["name"].(string),
+		Version: block["version"].(string),
+	}
+}
+
+func stackRoxTerraformComponentFromMessage(component *stackrox.StorageComponent) []interface{} {
+	if component == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"name":    component.Name,
+			"version": component.Version,
+		},
+	}
+}
+
+// stackRoxStringsFromTerraform returns the elements of a set of strings, or nil if the set is empty.
+func stackRoxStringsFromTerraform(data interface{}) []string {
+	set, ok := data.(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return nil
+	}
+
+	result := make([]string, 0, set.Len())
+	for _, e := range set.List() {
+		result = append(result, e.(string))
+	}
+
+	return result
+}
+
+func stackRoxVolumeFromTerraform(data interface{}) (*stackrox.StorageVolumePolicy, error) {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil, nil
+	}
+
+	readOnly, err := stackRoxBoolPtrFromTerraform(block["read_only"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid volume read_only: %v", err)
+	}
+
+	return &stackrox.StorageVolumePolicy{
+		Name:        block["name"].(string),
+		Source:      block["source"].(string),
+		Destination: block["destination"].(string),
+		Type:        block["type"].(string),
+		ReadOnly:    readOnly,
+	}, nil
+}
+
+func stackRoxTerraformVolumeFromMessage(volume *stackrox.StorageVolumePolicy) []interface{} {
+	if volume == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"name":        volume.Name,
+			"source":      volume.Source,
+			"destination": volume.Destination,
+			"type":        volume.Type,
+			"read_only":   stackRoxTerraformBoolFromMessage(volume.ReadOnly),
+		},
+	}
+}
+
+// The host mount criterion matches writable host mounts if `read_only` is false.
+func stackRoxHostMountFromTerraform(data interface{}) *stackrox.StorageHostMountPolicy {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil
+	}
+
+	readOnly := block["read_only"].(bool)
+	return &stackrox.StorageHostMountPolicy{
+		ReadOnly: &readOnly,
+	}
+}
+
+func stackRoxTerraformHostMountFromMessage(hostMount *stackrox.StorageHostMountPolicy) []interface{} {
+	if hostMount == nil || hostMount.ReadOnly == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"read_only": *hostMount.ReadOnly,
+		},
+	}
+}
+
+func stackRoxProcessFromTerraform(data interface{}) *stackrox.StorageProcessPolicy {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil
+	}
+
+	return &stackrox.StorageProcessPolicy{
+		Name:     block["name"].(string),
+		Args:     block["args"].(string),
+		Ancestor: block["ancestor"].(string),
+		Uid:      block["uid"].(string),
+	}
+}
+
+func stackRoxTerraformProcessFromMessage(process *stackrox.StorageProcessPolicy) []interface{} {
+	if process == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"name":     process.Name,
+			"args":     process.Args,
+			"ancestor": process.Ancestor,
+			"uid":      process.Uid,
+		},
+	}
+}
+
+func stackRoxKeyValueFromTerraform(data interface{}) *stackrox.StorageKeyValuePolicy {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil
+	}
+
+	result := &stackrox.StorageKeyValuePolicy{
+		Key:   block["key"].(string),
+		Value: block["value"].(string),
+	}
+	if source, ok := block["source"].(string); ok {
+		result.EnvVarSource = stackrox.EnvironmentConfigEnvVarSource(source)
+	}
+
+	return result
+}
+
+// stackRoxTerraformKeyValueFromMessage flattens a key/value criterion. The source is only part of the `env` block.
+func stackRoxTerraformKeyValueFromMessage(keyValue *stackrox.StorageKeyValuePolicy, withSource bool) []interface{} {
+	if keyValue == nil {
+		return []interface{}{}
+	}
+
+	block := map[string]interface{}{
+		"key":   keyValue.Key,
+		"value": keyValue.Value,
+	}
+	if withSource {
+		source := keyValue.EnvVarSource
+		if source == stackrox.ENVIRONMENTCONFIGENVVARSOURCE_UNSET {
+			source = ""
+		}
+		block["source"] = string(source)
+	}
+
+	return []interface{}{block}
+}
+
+func stackRoxDockerfileLineFromTerraform(data interface{}) *stackrox.StorageDockerfileLineRuleField {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil
+	}
+
+	return &stackrox.StorageDockerfileLineRuleField{
+		Instruction: block["instruction"].(string),
+		Value:       block["value"].(string),
+	}
+}
+
+func stackRoxTerraformDockerfileLineFromMessage(lineRule *stackrox.StorageDockerfileLineRuleField) []interface{} {
+	if lineRule == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"instruction": lineRule.Instruction,
+			"value":       lineRule.Value,
+		},
+	}
+}
+
+// Ages in days are int64 values, which the API transfers as strings. Zero means that the criterion isn't set.
+func stackRoxDaysFromTerraform(data interface{}) string {
+	days, ok := data.(int)
+	if !ok || days == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(days)
+}
+
+func stackRoxTerraformDaysFromMessage(days string) (int, error) {
+	if days == "" {
+		return 0, nil
+	}
+
+	result, err := strconv.Atoi(days)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number of days %q: %v", days, err)
+	}
+
+	return result, nil
+}
+
+var stackRoxComparatorSymbolToOp = map[string]stackrox.StorageComparator{
+	"=":  stackrox.STORAGECOMPARATOR_EQUALS,
+	">":  stackrox.STORAGECOMPARATOR_GREATER_THAN,
+	">=": stackrox.STORAGECOMPARATOR_GREATER_THAN_OR_EQUALS,
+	"<":  stackrox.STORAGECOMPARATOR_LESS_THAN,
+	"<=": stackrox.STORAGECOMPARATOR_LESS_THAN_OR_EQUALS,
+}
+
+var stackRoxComparatorOpToSymbol = map[stackrox.StorageComparator]string{
+	stackrox.STORAGECOMPARATOR_EQUALS:                 "=",
+	stackrox.STORAGECOMPARATOR_GREATER_THAN:           ">",
+	stackrox.STORAGECOMPARATOR_GREATER_THAN_OR_EQUALS: ">=",
+	stackrox.STORAGECOMPARATOR_LESS_THAN:              "<",
+	stackrox.STORAGECOMPARATOR_LESS_THAN_OR_EQUALS:    "<=",
+}
+
+// stackRoxNumericalPolicyFromTerraform parses numerical criteria, which are written as a comparator and a number,
+// e.g. ">= 3" or "< 0.5". It returns nil if the criterion isn't set.
+func stackRoxNumericalPolicyFromTerraform(data interface{}) (*stackrox.StorageNumericalPolicy, error) {
+	value, _ := data.(string)
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%q must be a comparator and a number, e.g. \">= 3\"", value)
+	}
+
+	op, ok := stackRoxComparatorSymbolToOp[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("%q has an unsupported comparator %q", value, parts[0])
+	}
+
+	number, err := strconv.ParseFloat(parts[1], 32)
+	if err != nil {
+		return nil, fmt.Errorf("%q has an invalid number %q", value, parts[1])
+	}
+
+	return &stackrox.StorageNumericalPolicy{
+		Op:    op,
+		Value: float32(number),
+	}, nil
+}
+
+func stackRoxTerraformNumericalPolicyFromMessage(policy *stackrox.StorageNumericalPolicy) (string, error) {
+	if policy == nil || policy.Op == "" {
+		return "", nil
+	}
+
+	op, ok := stackRoxComparatorOpToSymbol[policy.Op]
+	if !ok {
+		return "", fmt.Errorf("unsupported comparator %q", policy.Op)
+	}
+
+	return fmt.Sprintf("%s %s", op, strconv.FormatFloat(float64(policy.Value), 'f', -1, 32)), nil
+}
+
+func stackRoxPortFromTerraform(data interface{}) *stackrox.StoragePortPolicy {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil
+	}
+
+	return &stackrox.StoragePortPolicy{
+		Port:     int32(block["port"].(int)),
+		Protocol: block["protocol"].(string),
+	}
+}
+
+func stackRoxTerraformPortFromMessage(port *stackrox.StoragePortPolicy) []interface{} {
+	if port == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"port":     int(port.Port),
+			"protocol": port.Protocol,
+		},
+	}
+}
+
+func stackRoxPortExposureFromTerraform(data interface{}) *stackrox.StoragePortExposurePolicy {
+	levels := stackRoxStringsFromTerraform(data)
+	if len(levels) == 0 {
+		return nil
+	}
+
+	result := &stackrox.StoragePortExposurePolicy{}
+	for _, level := range levels {
+		result.ExposureLevels = append(result.ExposureLevels, stackrox.PortConfigExposureLevel(level))
+	}
+
+	return result
+}
+
+func stackRoxTerraformPortExposureFromMessage(portExposure *stackrox.StoragePortExposurePolicy) []string {
+	if portExposure == nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(portExposure.ExposureLevels))
+	for _, level := range portExposure.ExposureLevels {
+		result = append(result, string(level))
+	}
+
+	return result
+}
+
+func stackRoxContainerResourcesFromTerraform(data interface{}) (*stackrox.StorageResourcePolicy, error) {
+	block := stackRoxSingleBlockFromTerraform(data)
+	if block == nil {
+		return nil, nil
+	}
+
+	// Only the configured fields are set, the others are left out of the JSON.
+	result := &stackrox.StorageResourcePolicy{}
+	for key, field := range map[string]**stackrox.StorageNumericalPolicy{
+		"cpu_request":    &result.CpuResourceRequest,
+		"cpu_limit":      &result.CpuResourceLimit,
+		"memory_request": &result.MemoryResourceRequest,
+		"memory_limit":   &result.MemoryResourceLimit,
+	} {
+		policy, err := stackRoxNumericalPolicyFromTerraform(block[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		*field = policy
+	}
+
+	return result, nil
+}
+
+func stackRoxTerraformContainerResourcesFromMessage(resources *stackrox.StorageResourcePolicy) ([]interface{}, error) {
+	if resources == nil {
+		return []interface{}{}, nil
+	}
+
+	block := map[string]interface{}{}
+	for key, field := range map[string]*stackrox.StorageNumericalPolicy{
+		"cpu_request":    resources.CpuResourceRequest,
+		"cpu_limit":      resources.CpuResourceLimit,
+		"memory_request": resources.MemoryResourceRequest,
+		"memory_limit":   resources.MemoryResourceLimit,
+	} {
+		value, err := stackRoxTerraformNumericalPolicyFromMessage(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		block[key] = value
+	}
+
+	return []interface{}{block}, nil
+}
+
+func stackRoxPermissionFromTerraform(data interface{}) *stackrox.StoragePermissionPolicy {
+	level, _ := data.(string)
+	if level == "" {
+		return nil
+	}
+
+	return &stackrox.StoragePermissionPolicy{
+		PermissionLevel: stackrox.StoragePermissionLevel(level),
+	}
+}
+
+func stackRoxTerraformPermissionFromMessage(permission *stackrox.StoragePermissionPolicy) string {
+	if permission == nil || permission.PermissionLevel == stackrox.STORAGEPERMISSIONLEVEL_UNSET {
+		return ""
+	}
+
+	return string(permission.PermissionLevel)
+}
@@ -0,0 +1,490 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+func testStackRoxPolicyConfigRaw(criteria map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name":             "policy",
+		"description":      "description",
+		"rationale":        "rationale",
+		"remediation":      "remediation",
+		"categories":       []interface{}{"Security Best Practices"},
+		"lifecycle_stages": []interface{}{"DEPLOY"},
+		"severity":         "HIGH_SEVERITY",
+		"policy_criteria":  []interface{}{criteria},
+	}
+}
+
+// testStackRoxPolicyRoundTrip expands the criteria, flattens the resulting policy into a new state as on import,
+// and checks that expanding the state yields the same policy.
+func testStackRoxPolicyRoundTrip(t *testing.T, criteria map[string]interface{}) stackrox.StoragePolicy {
+	policySchema := resourceStackRoxPolicy().Schema
+
+	data := schema.TestResourceDataRaw(t, policySchema, testStackRoxPolicyConfigRaw(criteria))
+	message, err := stackRoxPolicyMessageFrom(data)
+	require.NoError(t, err)
+
+	imported := schema.TestResourceDataRaw(t, policySchema, map[string]interface{}{})
+	require.NoError(t, stackRoxPolicySetState(imported, message))
+
+	roundTripped, err := stackRoxPolicyMessageFrom(imported)
+	require.NoError(t, err)
+	assert.Equal(t, message, roundTripped)
+
+	return message
+}
+
+func TestStackRoxPolicyCriteria_image(t *testing.T) {
+	t.Parallel()
+
+	message := testStackRoxPolicyRoundTrip(t, map[string]interface{}{
+		"image_name": []interface{}{
+			map[string]interface{}{
+				"registry": "docker.io",
+				"remote":   "library/.*",
+				"tag":      "latest",
+			},
+		},
+		"image_age_days": 90,
+		"scan_age_days":  7,
+		"no_scan_exists": "true",
+	})
+
+	noScanExists := true
+	assert.Equal(t, stackrox.StoragePolicyFields{
+		ImageName: &stackrox.StorageImageNamePolicy{
+			Registry: "docker.io",
+			Remote:   "library/.*",
+			Tag:      "latest",
+		},
+		ImageAgeDays: "90",
+		ScanAgeDays:  "7",
+		NoScanExists: &noScanExists,
+	}, message.Fields)
+}
+
+func TestStackRoxPolicyCriteria_vulnerabilities(t *testing.T) {
+	t.Parallel()
+
+	message := testStackRoxPolicyRoundTrip(t, map[string]interface{}{
+		"cvss":     ">= 7",
+		"cve":      "CVE-2021-.*",
+		"fixed_by": ".*",
+		"component": []interface{}{
+			map[string]interface{}{
+				"name":    "openssl",
+				"version": "1\\.1\\.1[a-j]",
+			},
+		},
+	})
+
+	assert.Equal(t, "CVE-2021-.*", message.Fields.Cve)
+	assert.Equal(t, ".*", message.Fields.FixedBy)
+	assert.Equal(t, &stackrox.StorageComponent{Name: "openssl", Version: "1\\.1\\.1[a-j]"}, message.Fields.Component)
+}
+
+func TestStackRoxPolicyCriteria_containerConfiguration(t *testing.T) {
+	t.Parallel()
+
+	message := testStackRoxPolicyRoundTrip(t, map[string]interface{}{
+		"add_capabilities":  []interface{}{"SYS_ADMIN"},
+		"drop_capabilities": []interface{}{"NET_RAW"},
+		"read_only_root_fs": "false",
+		"volume": []interface{}{
+			map[string]interface{}{
+				"source":    "/var/run/docker.sock",
+				"read_only": "false",
+			},
+		},
+		"host_mount": []interface{}{
+			map[string]interface{}{
+				"read_only": false,
+			},
+		},
+		"user":      "0",
+		"directory": "/",
+	})
+
+	f := false
+	assert.Equal(t, stackrox.StoragePolicyFields{
+		AddCapabilities:  []string{"SYS_ADMIN"},
+		DropCapabilities: []string{"NET_RAW"},
+		ReadOnlyRootFs:   &f,
+		VolumePolicy: &stackrox.StorageVolumePolicy{
+			Source:   "/var/run/docker.sock",
+			ReadOnly: &f,
+		},
+		HostMountPolicy: &stackrox.StorageHostMountPolicy{ReadOnly: &f},
+		User:            "0",
+		Directory:       "/",
+	}, message.Fields)
+}
+
+func TestStackRoxPolicyCriteria_process(t *testing.T) {
+	t.Parallel()
+
+	message := testStackRoxPolicyRoundTrip(t, map[string]interface{}{
+		"process": []interface{}{
+			map[string]interface{}{
+				"name":     "bash|sh",
+				"ancestor": "java",
+			},
+		},
+		"process_baseline_violation": true,
+	})
+
+	assert.Equal(t, stackrox.StoragePolicyFields{
+		ProcessPolicy: &stackrox.StorageProcessPolicy{
+			Name:     "bash|sh",
+			Ancestor: "java",
+		},
+		WhitelistEnabled: true,
+	}, message.Fields)
+}
+
+func TestStackRoxPolicyCriteria_keyValue(t *testing.T) {
+	t.Parallel()
+
+	message := testStackRoxPolicyRoundTrip(t, map[string]interface{}{
+		"required_label": []interface{}{
+			map[string]interface{}{"key": "owner"},
+		},
+		"required_annotation": []interface{}{
+			map[string]interface{}{"key": "email", "value": ".+@example\\.com"},
+		},
+		"disallowed_annotation": []interface{}{
+			map[string]interface{}{"key": "debug"},
+		},
+		"env": []interface{}{
+			map[string]interface{}{"key": "AWS_SECRET_ACCESS_KEY", "source": "RAW"},
+		},
+	})
+
+	assert.Equal(t, stackrox.StoragePolicyFields{
+		RequiredLabel:        &stackrox.StorageKeyValuePolicy{Key: "owner"},
+		RequiredAnnotation:   &stackrox.StorageKeyValuePolicy{Key: "email", Value: ".+@example\\.com"},
+		DisallowedAnnotation: &stackrox.StorageKeyValuePolicy{Key: "debug"},
+		Env: &stackrox.StorageKeyValuePolicy{
+			Key:          "AWS_SECRET_ACCESS_KEY",
+			EnvVarSource: stackrox.ENVIRONMENTCONFIGENVVARSOURCE_RAW,
+		},
+	}, message.Fields)
+}
+
+func TestStackRoxPolicyCriteria_networkingAndResources(t *testing.T) {
+	t.Parallel()
+
+	message := testStackRoxPolicyRoundTrip(t, map[string]interface{}{
+		"port": []interface{}{
+			map[string]interface{}{"port": 22, "protocol": "TCP"},
+		},
+		"port_exposure": []interface{}{"EXTERNAL", "NODE"},
+		"container_resources": []interface{}{
+			map[string]interface{}{
+				"cpu_limit":      "> 2",
+				"memory_request": "< 0.5",
+			},
+		},
+		"permission_level": "CLUSTER_ADMIN",
+	})
+
+	assert.Equal(t, &stackrox.StoragePortPolicy{Port: 22, Protocol: "TCP"}, message.Fields.PortPolicy)
+	assert.ElementsMatch(t, []stackrox.PortConfigExposureLevel{
+		stackrox.PORTCONFIGEXPOSURELEVEL_EXTERNAL,
+		stackrox.PORTCONFIGEXPOSURELEVEL_NODE,
+	}, message.Fields.PortExposurePolicy.ExposureLevels)
+	assert.Equal(t, &stackrox.StorageResourcePolicy{
+		CpuResourceLimit:      &stackrox.StorageNumericalPolicy{Op: stackrox.STORAGECOMPARATOR_GREATER_THAN, Value: 2},
+		MemoryResourceRequest: &stackrox.StorageNumericalPolicy{Op: stackrox.STORAGECOMPARATOR_LESS_THAN, Value: 0.5},
+	}, message.Fields.ContainerResourcePolicy)
+
+	// The fields that aren't configured are left out.
+	resources, err := json.Marshal(message.Fields.ContainerResourcePolicy)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cpuResourceLimit":{"op":"GREATER_THAN","value":2},"memoryResourceRequest":{"op":"LESS_THAN","value":0.5}}`, string(resources))
+	assert.Equal(t, &stackrox.StoragePermissionPolicy{
+		PermissionLevel: stackrox.STORAGEPERMISSIONLEVEL_CLUSTER_ADMIN,
+	}, message.Fields.PermissionPolicy)
+}
+
+func TestStackRoxNumericalPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"= 0", "> 7.5", ">= 3", "< 100", "<= 0.25"} {
+		policy, err := stackRoxNumericalPolicyFromTerraform(value)
+		if assert.NoError(t, err, value) {
+			actual, err := stackRoxTerraformNumericalPolicyFromMessage(policy)
+			assert.NoError(t, err, value)
+			assert.Equal(t, value, actual)
+		}
+	}
+
+	for _, value := range []string{">=3", "~ 3", ">= three", ">= 3 4"} {
+		_, err := stackRoxNumericalPolicyFromTerraform(value)
+		assert.Error(t, err, value)
+	}
+
+	policy, err := stackRoxNumericalPolicyFromTerraform("")
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+}
+
+// testStackRoxPolicyPlan plans the creation of a policy with the given configuration.
+func testStackRoxPolicyPlan(raw map[string]interface{}) error {
+	_, err := resourceStackRoxPolicy().Diff(nil, terraform.NewResourceConfigRaw(raw), ClientWrap{})
+	return err
+}
+
+func TestStackRoxPolicyCriteria_dockerfileLine(t *testing.T) {
+	t.Parallel()
+
+	criteria := map[string]interface{}{
+		"dockerfile_line": []interface{}{
+			map[string]interface{}{"instruction": "ADD", "value": "https?://.*"},
+		},
+	}
+
+	message := testStackRoxPolicyRoundTrip(t, criteria)
+	assert.Equal(t, &stackrox.StorageDockerfileLineRuleField{
+		Instruction: "ADD",
+		Value:       "https?://.*",
+	}, message.Fields.LineRule)
+
+	config := testStackRoxPolicyConfigRaw(criteria)
+	err := testStackRoxPolicyPlan(config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "BUILD")
+	}
+
+	config["lifecycle_stages"] = []interface{}{"BUILD", "DEPLOY"}
+	assert.NoError(t, testStackRoxPolicyPlan(config))
+}
+
+func TestStackRoxPolicy_enforcementActions(t *testing.T) {
+	t.Parallel()
+
+	config := testStackRoxPolicyConfigRaw(map[string]interface{}{"cvss": ">= 7"})
+	config["enforcement_actions"] = []interface{}{"SCALE_TO_ZERO_ENFORCEMENT"}
+
+	policySchema := resourceStackRoxPolicy().Schema
+	message, err := stackRoxPolicyMessageFrom(schema.TestResourceDataRaw(t, policySchema, config))
+	require.NoError(t, err)
+	assert.Equal(t, []stackrox.StorageEnforcementAction{
+		stackrox.STORAGEENFORCEMENTACTION_SCALE_TO_ZERO_ENFORCEMENT,
+	}, message.EnforcementActions)
+
+	imported := schema.TestResourceDataRaw(t, policySchema, map[string]interface{}{})
+	require.NoError(t, stackRoxPolicySetState(imported, message))
+	assert.Equal(t, []interface{}{"SCALE_TO_ZERO_ENFORCEMENT"}, imported.Get("enforcement_actions").(*schema.Set).List())
+
+	assert.NoError(t, testStackRoxPolicyPlan(config))
+
+	tests := map[string]string{
+		"FAIL_BUILD_ENFORCEMENT": "BUILD",
+		"KILL_POD_ENFORCEMENT":   "RUNTIME",
+	}
+	for action, stage := range tests {
+		config["enforcement_actions"] = []interface{}{action}
+		err := testStackRoxPolicyPlan(config)
+		if assert.Error(t, err, action) {
+			assert.Contains(t, err.Error(), stage, action)
+		}
+	}
+
+	config["enforcement_actions"] = []interface{}{"FAIL_BUILD_ENFORCEMENT", "KILL_POD_ENFORCEMENT"}
+	config["lifecycle_stages"] = []interface{}{"BUILD", "RUNTIME"}
+	assert.NoError(t, testStackRoxPolicyPlan(config))
+}
+
+func TestStackRoxPolicy_scope(t *testing.T) {
+	t.Parallel()
+
+	config := testStackRoxPolicyConfigRaw(map[string]interface{}{"privileged": "true"})
+	config["scope"] = []interface{}{
+		map[string]interface{}{
+			"cluster":   "2f0a1b8c-3d4e-4f56-8a9b-0c1d2e3f4a5b",
+			"namespace": "team-.*",
+		},
+		map[string]interface{}{
+			"label": []interface{}{
+				map[string]interface{}{"key": "app", "value": "web"},
+			},
+		},
+	}
+
+	policySchema := resourceStackRoxPolicy().Schema
+	message, err := stackRoxPolicyMessageFrom(schema.TestResourceDataRaw(t, policySchema, config))
+	require.NoError(t, err)
+	assert.Equal(t, []stackrox.StorageScope{
+		{Cluster: "2f0a1b8c-3d4e-4f56-8a9b-0c1d2e3f4a5b", Namespace: "team-.*"},
+		{Label: &stackrox.StorageScopeLabel{Key: "app", Value: "web"}},
+	}, message.Scope)
+
+	// A scope without a label block doesn't constrain the labels.
+	scope, err := json.Marshal(message.Scope[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cluster":"2f0a1b8c-3d4e-4f56-8a9b-0c1d2e3f4a5b","namespace":"team-.*"}`, string(scope))
+
+	imported := schema.TestResourceDataRaw(t, policySchema, map[string]interface{}{})
+	require.NoError(t, stackRoxPolicySetState(imported, message))
+	roundTripped, err := stackRoxPolicyMessageFrom(imported)
+	require.NoError(t, err)
+	assert.Equal(t, message, roundTripped)
+}
+
+func TestStackRoxPolicyCriteria_invalidRegex(t *testing.T) {
+	t.Parallel()
+
+	criteria := stackRoxPolicyCriteriaSchema().Elem.(*schema.Resource).Schema
+	_, errs := criteria["cve"].ValidateFunc("CVE-(", "cve")
+	assert.NotEmpty(t, errs)
+}
+
+func TestStackRoxPolicyCriteria_unset(t *testing.T) {
+	t.Parallel()
+
+	message := testStackRoxPolicyRoundTrip(t, map[string]interface{}{
+		"cvss": ">= 7",
+	})
+
+	assert.Equal(t, stackrox.StoragePolicyFields{
+		Cvss: &stackrox.StorageNumericalPolicy{
+			Op:    stackrox.STORAGECOMPARATOR_GREATER_THAN_OR_EQUALS,
+			Value: 7,
+		},
+	}, message.Fields)
+}
+
+func TestStackRoxPolicy_validate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		criteria map[string]interface{}
+		override map[string]interface{}
+		error    string
+	}{
+		"severity": {
+			criteria: map[string]interface{}{"privileged": "true"},
+			override: map[string]interface{}{"severity": "EXTREME_SEVERITY"},
+			error:    "severity",
+		},
+		"lifecycle stage": {
+			criteria: map[string]interface{}{"privileged": "true"},
+			override: map[string]interface{}{"lifecycle_stages": []interface{}{"DEPLOY", "TESTING"}},
+			error:    "lifecycle_stages",
+		},
+		"comparator without space": {
+			criteria: map[string]interface{}{"cvss": ">=3"},
+			error:    `policy_criteria.0.cvss: ">=3" must be a comparator and a number`,
+		},
+		"unsupported comparator": {
+			criteria: map[string]interface{}{
+				"container_resources": []interface{}{
+					map[string]interface{}{"cpu_limit": "!= 2"},
+				},
+			},
+			error: `policy_criteria.0.container_resources.0.cpu_limit: "!= 2" has an unsupported comparator "!="`,
+		},
+		"bool": {
+			criteria: map[string]interface{}{"privileged": "yes"},
+			error:    `policy_criteria.0.privileged: "yes" must be "true" or "false"`,
+		},
+		"regex": {
+			criteria: map[string]interface{}{
+				"image_name": []interface{}{
+					map[string]interface{}{"tag": "latest("},
+				},
+			},
+			error: "policy_criteria.0.image_name.0.tag",
+		},
+	}
+
+	for name, test := range tests {
+		config := testStackRoxPolicyConfigRaw(test.criteria)
+		for k, v := range test.override {
+			config[k] = v
+		}
+
+		_, errors := resourceStackRoxPolicy().Validate(terraform.NewResourceConfigRaw(config))
+		if assert.Len(t, errors, 1, name) {
+			assert.Contains(t, errors[0].Error(), test.error, name)
+		}
+	}
+}
+
+func TestStackRoxPolicy_planCriteriaLifecycleStages(t *testing.T) {
+	t.Parallel()
+
+	config := testStackRoxPolicyConfigRaw(map[string]interface{}{
+		"process": []interface{}{
+			map[string]interface{}{"name": "bash"},
+		},
+	})
+	err := testStackRoxPolicyPlan(config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "policy_criteria.0.process requires the RUNTIME lifecycle stage")
+	}
+
+	config["lifecycle_stages"] = []interface{}{"RUNTIME"}
+	assert.NoError(t, testStackRoxPolicyPlan(config))
+
+	config = testStackRoxPolicyConfigRaw(map[string]interface{}{"process_baseline_violation": true})
+	err = testStackRoxPolicyPlan(config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "policy_criteria.0.process_baseline_violation requires the RUNTIME lifecycle stage")
+	}
+
+	config = testStackRoxPolicyConfigRaw(map[string]interface{}{"privileged": "true"})
+	config["lifecycle_stages"] = []interface{}{"BUILD"}
+	err = testStackRoxPolicyPlan(config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "policy_criteria.0.privileged requires the DEPLOY or RUNTIME lifecycle stage")
+	}
+
+	// Image criteria can be evaluated in every lifecycle stage.
+	config = testStackRoxPolicyConfigRaw(map[string]interface{}{"cve": "CVE-2021-.*"})
+	config["lifecycle_stages"] = []interface{}{"BUILD"}
+	assert.NoError(t, testStackRoxPolicyPlan(config))
+}
+
+func TestStackRoxBoolPtrFromTerraform(t *testing.T) {
+	t.Parallel()
+
+	value, err := stackRoxBoolPtrFromTerraform("")
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = stackRoxBoolPtrFromTerraform("false")
+	if assert.NoError(t, err) && assert.NotNil(t, value) {
+		assert.False(t, *value)
+	}
+
+	_, err = stackRoxBoolPtrFromTerraform("1")
+	assert.Error(t, err)
+}
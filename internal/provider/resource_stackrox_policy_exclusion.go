@@ -0,0 +1,239 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// stackRoxExclusionResource is the schema of an exclusion, which is called a whitelist by the API. An exclusion
+// exempts deployments, optionally limited to a scope, or images from a policy until it expires.
+func stackRoxExclusionResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"deployment": {
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"scope": {
+							Type:     schema.TypeList,
+							MaxItems: 1,
+							Optional: true,
+							Elem:     stackRoxScopeResource(),
+						},
+					},
+				},
+			},
+			"image": {
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+			"expiration": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: stackRoxSuppressEqualTimes,
+			},
+		},
+	}
+}
+
+// stackRoxSuppressEqualTimes suppresses diffs between timestamps that denote the same instant, because Central
+// returns them in UTC.
+func stackRoxSuppressEqualTimes(k, old, new string, d *schema.ResourceData) bool {
+	oldTime, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+
+	newTime, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+
+	return oldTime.Equal(newTime)
+}
+
+// stackRoxExclusionsFromTerraform expands the exclusions. Exclusions that expired before now are left out,
+// because Central drops them anyway.
+func stackRoxExclusionsFromTerraform(l []interface{}, now time.Time) ([]stackrox.StorageWhitelist, error) {
+	result := make([]stackrox.StorageWhitelist, 0, len(l))
+
+	for _, e := range l {
+		block, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("an exclusion must exclude a deployment or an image")
+		}
+
+		exclusion, err := stackRoxExclusionFromTerraform(block)
+		if err != nil {
+			return nil, err
+		}
+
+		if exclusion.Expiration != nil && !exclusion.Expiration.After(now) {
+			debug(fmt.Sprintf("skipping exclusion %q, which expired at %v", exclusion.Name, exclusion.Expiration))
+			continue
+		}
+
+		result = append(result, exclusion)
+	}
+
+	return result, nil
+}
+
+func stackRoxExclusionFromTerraform(block map[string]interface{}) (stackrox.StorageWhitelist, error) {
+	exclusion := stackrox.StorageWhitelist{
+		Name: block["name"].(string),
+	}
+
+	if deployment, ok := block["deployment"].([]interface{}); ok && len(deployment) > 0 {
+		// An empty deployment block excludes all deployments.
+		exclusion.Deployment = &stackrox.StorageWhitelistDeployment{}
+		if deployment[0] != nil {
+			d := deployment[0].(map[string]interface{})
+			exclusion.Deployment.Name = d["name"].(string)
+			if scope := stackRoxSingleBlockFromTerraform(d["scope"]); scope != nil {
+				deploymentScope := stackRoxScopeFromTerraform(scope)
+				exclusion.Deployment.Scope = &deploymentScope
+			}
+		}
+	}
+
+	if image := stackRoxSingleBlockFromTerraform(block["image"]); image != nil {
+		exclusion.Image = &stackrox.StorageWhitelistImage{
+			Name: image["name"].(string),
+		}
+	}
+
+	if exclusion.Deployment == nil && exclusion.Image == nil {
+		return exclusion, fmt.Errorf("exclusion %q must exclude a deployment or an image", exclusion.Name)
+	}
+
+	if expiration := block["expiration"].(string); expiration != "" {
+		t, err := time.Parse(time.RFC3339, expiration)
+		if err != nil {
+			return exclusion, fmt.Errorf("invalid expiration of exclusion %q: %v", exclusion.Name, err)
+		}
+		exclusion.Expiration = &t
+	}
+
+	return exclusion, nil
+}
+
+// stackRoxTerraformExclusionsFromMessage flattens the exclusions of a policy. The exclusions in the prior state
+// that expired before now are kept at their position, so that configured exclusions don't cause a diff once they
+// expire.
+func stackRoxTerraformExclusionsFromMessage(exclusions []stackrox.StorageWhitelist, prior []interface{}, now time.Time) []interface{} {
+	result := make([]interface{}, 0, len(exclusions))
+	for _, exclusion := range exclusions {
+		result = append(result, stackRoxTerraformExclusionFromMessage(exclusion))
+	}
+
+	for i, e := range prior {
+		block, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		exclusion, err := stackRoxExclusionFromTerraform(block)
+		if err != nil || exclusion.Expiration == nil || exclusion.Expiration.After(now) {
+			continue
+		}
+
+		expired := stackRoxTerraformExclusionFromMessage(exclusion)
+		if stackRoxContainsBlock(result, expired) {
+			continue
+		}
+
+		if i > len(result) {
+			i = len(result)
+		}
+		result = append(result[:i], append([]interface{}{block}, result[i:]...)...)
+	}
+
+	return result
+}
+
+func stackRoxTerraformExclusionFromMessage(exclusion stackrox.StorageWhitelist) map[string]interface{} {
+	deployment := []interface{}{}
+	if exclusion.Deployment != nil {
+		scope := []interface{}{}
+		if exclusion.Deployment.Scope != nil {
+			scope = append(scope, stackRoxTerraformScopeFromMessage(*exclusion.Deployment.Scope))
+		}
+
+		deployment = append(deployment, map[string]interface{}{
+			"name":  exclusion.Deployment.Name,
+			"scope": scope,
+		})
+	}
+
+	image := []interface{}{}
+	if exclusion.Image != nil {
+		image = append(image, map[string]interface{}{
+			"name": exclusion.Image.Name,
+		})
+	}
+
+	expiration := ""
+	if exclusion.Expiration != nil && !exclusion.Expiration.IsZero() {
+		expiration = exclusion.Expiration.UTC().Format(time.RFC3339)
+	}
+
+	return map[string]interface{}{
+		"name":       exclusion.Name,
+		"deployment": deployment,
+		"image":      image,
+		"expiration": expiration,
+	}
+}
+
+func stackRoxContainsBlock(blocks []interface{}, block map[string]interface{}) bool {
+	for _, b := range blocks {
+		if reflect.DeepEqual(b, block) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,147 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+var (
+	testExclusionNow     = time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	testExclusionExpired = map[string]interface{}{
+		"name": "expired",
+		"image": []interface{}{
+			map[string]interface{}{"name": "docker.io/library/nginx:1.19"},
+		},
+		"expiration": "2021-05-01T12:00:00+02:00",
+	}
+	testExclusionDeployment = map[string]interface{}{
+		"name": "legacy",
+		"deployment": []interface{}{
+			map[string]interface{}{
+				"name": "legacy-app",
+				"scope": []interface{}{
+					map[string]interface{}{"namespace": "legacy"},
+				},
+			},
+		},
+		"expiration": "2021-12-31T00:00:00Z",
+	}
+)
+
+func TestStackRoxExclusions_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	config := testStackRoxPolicyConfigRaw(map[string]interface{}{"privileged": "true"})
+	config["exclusion"] = []interface{}{testExclusionDeployment}
+
+	policySchema := resourceStackRoxPolicy().Schema
+	data := schema.TestResourceDataRaw(t, policySchema, config)
+	exclusions, err := stackRoxExclusionsFromTerraform(data.Get("exclusion").([]interface{}), testExclusionNow)
+	require.NoError(t, err)
+
+	expiration := time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []stackrox.StorageWhitelist{
+		{
+			Name: "legacy",
+			Deployment: &stackrox.StorageWhitelistDeployment{
+				Name:  "legacy-app",
+				Scope: &stackrox.StorageScope{Namespace: "legacy"},
+			},
+			Expiration: &expiration,
+		},
+	}, exclusions)
+
+	flattened := stackRoxTerraformExclusionsFromMessage(exclusions, nil, testExclusionNow)
+	require.NoError(t, data.Set("exclusion", flattened))
+	assert.Equal(t, config["exclusion"].([]interface{})[0].(map[string]interface{})["expiration"], data.Get("exclusion.0.expiration"))
+	assert.Equal(t, "legacy", data.Get("exclusion.0.deployment.0.scope.0.namespace"))
+}
+
+func TestStackRoxExclusions_deploymentWithoutScope(t *testing.T) {
+	t.Parallel()
+
+	exclusion, err := stackRoxExclusionFromTerraform(map[string]interface{}{
+		"name": "legacy",
+		"deployment": []interface{}{
+			map[string]interface{}{"name": "legacy-app", "scope": []interface{}{}},
+		},
+		"image":      []interface{}{},
+		"expiration": "",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, exclusion.Deployment.Scope)
+
+	// The deployment isn't restricted to an empty scope.
+	deployment, err := json.Marshal(exclusion.Deployment)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"legacy-app"}`, string(deployment))
+
+	flattened := stackRoxTerraformExclusionFromMessage(exclusion)
+	assert.Empty(t, flattened["deployment"].([]interface{})[0].(map[string]interface{})["scope"])
+}
+
+func TestStackRoxExclusions_expired(t *testing.T) {
+	t.Parallel()
+
+	config := testStackRoxPolicyConfigRaw(map[string]interface{}{"privileged": "true"})
+	config["exclusion"] = []interface{}{testExclusionExpired, testExclusionDeployment}
+
+	data := schema.TestResourceDataRaw(t, resourceStackRoxPolicy().Schema, config)
+	prior := data.Get("exclusion").([]interface{})
+
+	// Expired exclusions aren't sent to Central.
+	exclusions, err := stackRoxExclusionsFromTerraform(prior, testExclusionNow)
+	require.NoError(t, err)
+	if assert.Len(t, exclusions, 1) {
+		assert.Equal(t, "legacy", exclusions[0].Name)
+	}
+
+	// They're kept in the state at their position, once.
+	flattened := stackRoxTerraformExclusionsFromMessage(exclusions, prior, testExclusionNow)
+	assert.Equal(t, prior, flattened)
+
+	expired, err := stackRoxExclusionFromTerraform(testExclusionExpired)
+	require.NoError(t, err)
+	flattened = stackRoxTerraformExclusionsFromMessage(append([]stackrox.StorageWhitelist{expired}, exclusions...), prior, testExclusionNow)
+	assert.Len(t, flattened, 2)
+}
+
+func TestStackRoxExclusions_invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := stackRoxExclusionsFromTerraform([]interface{}{
+		map[string]interface{}{"name": "nothing", "deployment": []interface{}{}, "image": []interface{}{}, "expiration": ""},
+	}, testExclusionNow)
+	assert.Error(t, err)
+}
+
+func TestStackRoxSuppressEqualTimes(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, stackRoxSuppressEqualTimes("", "2021-05-01T10:00:00Z", "2021-05-01T12:00:00+02:00", nil))
+	assert.False(t, stackRoxSuppressEqualTimes("", "2021-05-01T10:00:00Z", "2021-05-01T10:00:00+02:00", nil))
+	assert.False(t, stackRoxSuppressEqualTimes("", "", "2021-05-01T10:00:00Z", nil))
+}
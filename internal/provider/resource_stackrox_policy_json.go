@@ -0,0 +1,257 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// stackRoxPolicyServerManagedFields are the fields of exported policies that are set by Central. They're ignored
+// when policies are compared and created.
+var stackRoxPolicyServerManagedFields = []string{"id", "lastUpdated"}
+
+// stackRoxPolicyJSONFields returns the fields of a policy as exported by Central, without the server-managed
+// fields. Both a single policy and an export of exactly one policy, i.e. `{"policies": [...]}`, are accepted.
+func stackRoxPolicyJSONFields(data string) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("invalid policy JSON: %v", err)
+	}
+
+	if policies, ok := raw["policies"]; ok {
+		var export []json.RawMessage
+		if err := json.Unmarshal(policies, &export); err != nil {
+			return nil, fmt.Errorf("invalid policy export: %v", err)
+		}
+		if len(export) != 1 {
+			return nil, fmt.Errorf("the policy export must contain exactly one policy, found %d", len(export))
+		}
+		raw = nil
+		if err := json.Unmarshal(export[0], &raw); err != nil {
+			return nil, fmt.Errorf("invalid policy JSON: %v", err)
+		}
+	}
+
+	for _, field := range stackRoxPolicyServerManagedFields {
+		delete(raw, field)
+	}
+
+	return raw, nil
+}
+
+// stackRoxPolicyFromJSON parses a policy as exported by Central. The server-managed fields are cleared.
+func stackRoxPolicyFromJSON(data string) (stackrox.StoragePolicy, error) {
+	raw, err := stackRoxPolicyJSONFields(data)
+	if err != nil {
+		return stackrox.StoragePolicy{}, err
+	}
+
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return stackrox.StoragePolicy{}, err
+	}
+
+	var policy stackrox.StoragePolicy
+	if err := json.Unmarshal(normalized, &policy); err != nil {
+		return stackrox.StoragePolicy{}, fmt.Errorf("invalid policy JSON: %v", err)
+	}
+
+	return policy, nil
+}
+
+// stackRoxUnknownPolicyFields returns the top-level fields of a policy that the API client doesn't support.
+func stackRoxUnknownPolicyFields(raw map[string]json.RawMessage) []string {
+	known := map[string]bool{}
+	policyType := reflect.TypeOf(stackrox.StoragePolicy{})
+	for i := 0; i < policyType.NumField(); i++ {
+		name := strings.Split(policyType.Field(i).Tag.Get("json"), ",")[0]
+		known[name] = true
+	}
+
+	var result []string
+	for field := range raw {
+		if !known[field] {
+			result = append(result, field)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// stackRoxCanonicalPolicyJSON returns the canonical JSON of a policy: server-managed fields are removed, empty and
+// default values are pruned, and the keys are sorted. Policies that are semantically equal have the same canonical
+// JSON.
+func stackRoxCanonicalPolicyJSON(policy stackrox.StoragePolicy) (string, error) {
+	fields, err := stackRoxCanonicalPolicyFields(policy)
+	if err != nil {
+		return "", err
+	}
+
+	// encoding/json sorts the keys of maps.
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// stackRoxPolicySetFields are the arrays of a policy whose order doesn't matter.
+var stackRoxPolicySetFields = []string{"categories", "lifecycleStages", "notifiers", "enforcementActions"}
+
+// stackRoxPolicyDefaultFields are the top-level fields of a policy whose zero value is the default.
+var stackRoxPolicyDefaultFields = []string{"disabled"}
+
+// stackRoxCanonicalPolicyFields returns the fields of the canonical JSON of a policy.
+func stackRoxCanonicalPolicyFields(policy stackrox.StoragePolicy) (map[string]interface{}, error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	for _, field := range stackRoxPolicyServerManagedFields {
+		delete(raw, field)
+	}
+
+	for _, field := range stackRoxPolicyDefaultFields {
+		if value, ok := raw[field].(bool); ok && !value {
+			delete(raw, field)
+		}
+	}
+
+	for _, field := range stackRoxPolicySetFields {
+		stackRoxSortStrings(raw[field])
+	}
+
+	result, ok := stackRoxPruneEmpty(raw).(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+
+	return result, nil
+}
+
+// stackRoxSortStrings sorts an array of strings in place.
+func stackRoxSortStrings(v interface{}) {
+	values, ok := v.([]interface{})
+	if !ok {
+		return
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		a, _ := values[i].(string)
+		b, _ := values[j].(string)
+		return a < b
+	})
+}
+
+// stackRoxPruneEmpty recursively removes null, empty strings, empty objects and empty arrays, which Central treats
+// like missing values. Booleans are kept, because `false` is a condition of the tri-state criteria, e.g.
+// `readOnlyRootFs`.
+func stackRoxPruneEmpty(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for k, e := range value {
+			if pruned := stackRoxPruneEmpty(e); pruned != nil {
+				result[k] = pruned
+			}
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(value))
+		for _, e := range value {
+			if pruned := stackRoxPruneEmpty(e); pruned != nil {
+				result = append(result, pruned)
+			}
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	case string:
+		if value == "" {
+			return nil
+		}
+	}
+
+	return v
+}
+
+func validateStackRoxPolicyJSON(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+
+	if _, err := stackRoxPolicyFromJSON(v); err != nil {
+		errors = append(errors, fmt.Errorf("%s: %v", k, err))
+		return
+	}
+
+	// Exports of newer versions of Central may have fields that this provider can't manage.
+	raw, _ := stackRoxPolicyJSONFields(v)
+	if ignored := stackRoxUnknownPolicyFields(raw); len(ignored) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: ignoring unsupported policy fields: %s", k, strings.Join(ignored, ", ")))
+	}
+
+	return
+}
+
+// stackRoxSuppressEquivalentPolicyJSON suppresses diffs between policies with the same canonical JSON, e.g. if
+// only the server-managed fields or the formatting differ.
+func stackRoxSuppressEquivalentPolicyJSON(k, old, new string, d *schema.ResourceData) bool {
+	oldPolicy, err := stackRoxPolicyFromJSON(old)
+	if err != nil {
+		return false
+	}
+
+	newPolicy, err := stackRoxPolicyFromJSON(new)
+	if err != nil {
+		return false
+	}
+
+	oldJSON, err := stackRoxCanonicalPolicyJSON(oldPolicy)
+	if err != nil {
+		return false
+	}
+
+	newJSON, err := stackRoxCanonicalPolicyJSON(newPolicy)
+	if err != nil {
+		return false
+	}
+
+	return oldJSON == newJSON
+}
@@ -0,0 +1,172 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+const testStackRoxPolicyJSON = `{
+  "id": "a919ccaf-6b43-4160-ac5d-a405e1440a41",
+  "name": "Privileged Container",
+  "description": "Alert on deployments with containers running in privileged mode",
+  "rationale": "Privileged containers have access to all devices of the host",
+  "remediation": "Run the container without the privileged flag",
+  "disabled": false,
+  "categories": ["Privileges"],
+  "lifecycleStages": ["DEPLOY"],
+  "severity": "MEDIUM_SEVERITY",
+  "fields": {
+    "privileged": true
+  },
+  "lastUpdated": "2021-03-01T10:00:00Z"
+}`
+
+func TestStackRoxPolicyFromJSON(t *testing.T) {
+	t.Parallel()
+
+	policy, err := stackRoxPolicyFromJSON(testStackRoxPolicyJSON)
+	require.NoError(t, err)
+
+	assert.Empty(t, policy.Id)
+	assert.True(t, policy.LastUpdated.IsZero())
+	assert.Equal(t, "Privileged Container", policy.Name)
+	assert.Equal(t, []stackrox.StorageLifecycleStage{"DEPLOY"}, policy.LifecycleStages)
+	assert.Equal(t, stackrox.StorageSeverity("MEDIUM_SEVERITY"), policy.Severity)
+	assert.Equal(t, "true", stackRoxTerraformBoolFromMessage(policy.Fields.Privileged))
+}
+
+func TestStackRoxPolicyFromJSON_export(t *testing.T) {
+	t.Parallel()
+
+	policy, err := stackRoxPolicyFromJSON(`{"policies": [` + testStackRoxPolicyJSON + `]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Privileged Container", policy.Name)
+	assert.Empty(t, policy.Id)
+
+	_, err = stackRoxPolicyFromJSON(`{"policies": [` + testStackRoxPolicyJSON + `, ` + testStackRoxPolicyJSON + `]}`)
+	assert.EqualError(t, err, "the policy export must contain exactly one policy, found 2")
+
+	_, err = stackRoxPolicyFromJSON(`{"name": `)
+	assert.Error(t, err)
+}
+
+func TestStackRoxCanonicalPolicyJSON(t *testing.T) {
+	t.Parallel()
+
+	exported, err := stackRoxPolicyFromJSON(testStackRoxPolicyJSON)
+	require.NoError(t, err)
+
+	// Central sets the ID and the last update, and omits defaults.
+	central := exported
+	central.Id = "d3e2b5a1-2c3c-4b8e-9a8c-4d5c2d1e0f11"
+
+	exportedJSON, err := stackRoxCanonicalPolicyJSON(exported)
+	require.NoError(t, err)
+	centralJSON, err := stackRoxCanonicalPolicyJSON(central)
+	require.NoError(t, err)
+
+	assert.Equal(t, exportedJSON, centralJSON)
+	assert.NotContains(t, exportedJSON, "id")
+	assert.NotContains(t, exportedJSON, "lastUpdated")
+	assert.NotContains(t, exportedJSON, "disabled")
+
+	reformatted := `{"categories":["Privileges"],"description":"Alert on deployments with containers running in privileged mode",` +
+		`"fields":{"privileged":true},"lifecycleStages":["DEPLOY"],"name":"Privileged Container",` +
+		`"rationale":"Privileged containers have access to all devices of the host",` +
+		`"remediation":"Run the container without the privileged flag","severity":"MEDIUM_SEVERITY"}`
+	assert.Equal(t, reformatted, exportedJSON)
+	assert.True(t, stackRoxSuppressEquivalentPolicyJSON("policy_json", testStackRoxPolicyJSON, reformatted, nil))
+
+	changed := `{"name":"Privileged Container","severity":"HIGH_SEVERITY"}`
+	assert.False(t, stackRoxSuppressEquivalentPolicyJSON("policy_json", testStackRoxPolicyJSON, changed, nil))
+}
+
+func TestValidateStackRoxPolicyJSON(t *testing.T) {
+	t.Parallel()
+
+	warnings, errors := validateStackRoxPolicyJSON(testStackRoxPolicyJSON, "policy_json")
+	assert.Empty(t, warnings)
+	assert.Empty(t, errors)
+
+	warnings, errors = validateStackRoxPolicyJSON(`{"name": "policy", "policyVersion": "1.1", "criteriaLocked": true}`, "policy_json")
+	assert.Equal(t, []string{"policy_json: ignoring unsupported policy fields: criteriaLocked, policyVersion"}, warnings)
+	assert.Empty(t, errors)
+
+	_, errors = validateStackRoxPolicyJSON(`[]`, "policy_json")
+	assert.Len(t, errors, 1)
+}
+
+func TestStackRoxPolicy_planPolicyJSON(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, testStackRoxPolicyPlan(map[string]interface{}{
+		"policy_json": testStackRoxPolicyJSON,
+	}))
+
+	config := testStackRoxPolicyConfigRaw(map[string]interface{}{"privileged": "true"})
+	config["policy_json"] = testStackRoxPolicyJSON
+	_, errors := resourceStackRoxPolicy().Validate(terraform.NewResourceConfigRaw(config))
+	assert.NotEmpty(t, errors)
+
+	config = testStackRoxPolicyConfigRaw(map[string]interface{}{"privileged": "true"})
+	delete(config, "name")
+	err := testStackRoxPolicyPlan(config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `"name" is required unless`)
+	}
+}
+
+func TestStackRoxCanonicalPolicyJSON_falseCriteria(t *testing.T) {
+	t.Parallel()
+
+	policyJSON := `{
+  "name": "Writable Host Mount",
+  "disabled": false,
+  "fields": {
+    "readOnlyRootFs": false,
+    "hostMountPolicy": {"readOnly": false}
+  }
+}`
+	policy, err := stackRoxPolicyFromJSON(policyJSON)
+	require.NoError(t, err)
+
+	canonical, err := stackRoxCanonicalPolicyJSON(policy)
+	require.NoError(t, err)
+	assert.Equal(t, `{"fields":{"hostMountPolicy":{"readOnly":false},"readOnlyRootFs":false},"name":"Writable Host Mount"}`, canonical)
+
+	// Removing a false criterion changes the policy.
+	assert.False(t, stackRoxSuppressEquivalentPolicyJSON("policy_json", policyJSON, `{"name": "Writable Host Mount"}`, nil))
+	assert.False(t, stackRoxSuppressEquivalentPolicyJSON("policy_json", policyJSON,
+		`{"name": "Writable Host Mount", "fields": {"readOnlyRootFs": false}}`, nil))
+}
+
+func TestStackRoxCanonicalPolicyJSON_setOrder(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, stackRoxSuppressEquivalentPolicyJSON("policy_json",
+		`{"name": "policy", "categories": ["B", "A"], "lifecycleStages": ["RUNTIME", "DEPLOY"], "notifiers": ["n2", "n1"]}`,
+		`{"name": "policy", "categories": ["A", "B"], "lifecycleStages": ["DEPLOY", "RUNTIME"], "notifiers": ["n1", "n2"]}`,
+		nil,
+	))
+}
@@ -0,0 +1,119 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// stackRoxScopeResource is the schema of a scope, which restricts a policy to deployments in a cluster, in
+// namespaces, or with a label. The cluster is the ID of the cluster, e.g. of a `stackrox_kubernetes_cluster`.
+func stackRoxScopeResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"cluster": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"namespace": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"label": {
+				Type:     schema.TypeList,
+				MaxItems: 1,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsValidRegExp,
+						},
+						"value": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringIsValidRegExp,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func stackRoxScopesFromTerraform(l []interface{}) []stackrox.StorageScope {
+	result := make([]stackrox.StorageScope, 0, len(l))
+
+	for _, e := range l {
+		block, ok := e.(map[string]interface{})
+		if !ok {
+			// An empty block, which matches every deployment.
+			result = append(result, stackrox.StorageScope{})
+			continue
+		}
+		result = append(result, stackRoxScopeFromTerraform(block))
+	}
+
+	return result
+}
+
+func stackRoxScopeFromTerraform(block map[string]interface{}) stackrox.StorageScope {
+	scope := stackrox.StorageScope{
+		Cluster:   block["cluster"].(string),
+		Namespace: block["namespace"].(string),
+	}
+
+	if label := stackRoxSingleBlockFromTerraform(block["label"]); label != nil {
+		scope.Label = &stackrox.StorageScopeLabel{
+			Key:   label["key"].(string),
+			Value: label["value"].(string),
+		}
+	}
+
+	return scope
+}
+
+func stackRoxTerraformScopesFromMessage(scopes []stackrox.StorageScope) []interface{} {
+	result := make([]interface{}, 0, len(scopes))
+
+	for _, scope := range scopes {
+		result = append(result, stackRoxTerraformScopeFromMessage(scope))
+	}
+
+	return result
+}
+
+func stackRoxTerraformScopeFromMessage(scope stackrox.StorageScope) map[string]interface{} {
+	label := []interface{}{}
+	if scope.Label != nil {
+		label = append(label, map[string]interface{}{
+			"key":   scope.Label.Key,
+			"value": scope.Label.Value,
+		})
+	}
+
+	return map[string]interface{}{
+		"cluster":   scope.Cluster,
+		"namespace": scope.Namespace,
+		"label":     label,
+	}
+}
@@ -17,11 +17,13 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -39,6 +41,7 @@ func TestAccStackRoxPolicy_basic(t *testing.T) {
 	var policy stackrox.StoragePolicy
 
 	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
 		Providers: testAccProviders(),
 		Steps: []resource.TestStep{
 			// Exercise the plan, apply, refresh, and destroy life cycles.
@@ -64,6 +67,144 @@ func TestAccStackRoxPolicy_basic(t *testing.T) {
 					testAccCheckStackRoxPolicyExists(resourceName, &policy),
 					testAccCheckStackRoxPolicyResourceAttributes(testAccStackRoxPolicyAddress(resourceName), &policy),
 					resource.TestCheckResourceAttr(testAccStackRoxPolicyAddress(resourceName), "notifiers.#", "1"),
+					resource.TestCheckResourceAttr(testAccStackRoxPolicyAddress(resourceName), "enforcement_actions.#", "1"),
+				),
+			},
+		},
+		CheckDestroy: testAccCheckStackRoxPolicyWasDestroyed(resourceName),
+	})
+}
+
+func TestAccStackRoxPolicy_imageCriteria(t *testing.T) {
+	resourceName := acctest.RandomWithPrefix("testacc-policy")
+	address := testAccStackRoxPolicyAddress(resourceName)
+
+	var policy stackrox.StoragePolicy
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStackRoxPolicyConfigImageCriteria(resourceName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxPolicyExists(resourceName, &policy),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.image_name.0.registry", "docker.io"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.image_name.0.tag", "latest"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.image_age_days", "90"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.scan_age_days", "7"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.no_scan_exists", "true"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.cve", "CVE-2021-.*"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.fixed_by", ".*"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.component.0.name", "openssl"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.required_label.0.key", "owner"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.dockerfile_line.0.instruction", "ADD"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.env.0.source", "RAW"),
+				),
+			},
+			{
+				ResourceName:      address,
+				Config:            testAccStackRoxProviderConfig(),
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testAccCheckStackRoxPolicyWasDestroyed(resourceName),
+	})
+}
+
+func TestAccStackRoxPolicy_processCriteria(t *testing.T) {
+	resourceName := acctest.RandomWithPrefix("testacc-policy")
+	address := testAccStackRoxPolicyAddress(resourceName)
+
+	var policy stackrox.StoragePolicy
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStackRoxPolicyConfigProcessCriteria(resourceName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxPolicyExists(resourceName, &policy),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.process.0.name", "bash|sh"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.process.0.ancestor", "java"),
+					resource.TestCheckResourceAttr(address, "policy_criteria.0.process_baseline_violation", "false"),
+				),
+			},
+			{
+				ResourceName:      address,
+				Config:            testAccStackRoxProviderConfig(),
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testAccCheckStackRoxPolicyWasDestroyed(resourceName),
+	})
+}
+
+func TestAccStackRoxPolicy_scope(t *testing.T) {
+	resourceName := acctest.RandomWithPrefix("testacc-policy")
+	address := testAccStackRoxPolicyAddress(resourceName)
+
+	var policy stackrox.StoragePolicy
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStackRoxPolicyConfigScope(resourceName, uuid.New().String()),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxPolicyExists(resourceName, &policy),
+					resource.TestCheckResourceAttr(address, "scope.#", "2"),
+					resource.TestCheckResourceAttrPair(address, "scope.0.cluster", testAccStackRoxClusterResourceAddress(resourceName), "id"),
+					resource.TestCheckResourceAttr(address, "scope.0.namespace", "team-.*"),
+					resource.TestCheckResourceAttr(address, "scope.1.label.0.key", "app"),
+					resource.TestCheckResourceAttr(address, "exclusion.0.deployment.0.name", "legacy-app"),
+					resource.TestCheckResourceAttr(address, "exclusion.0.expiration", "2099-12-31T00:00:00Z"),
+				),
+			},
+			{
+				ResourceName:      address,
+				Config:            testAccStackRoxProviderConfig(),
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		CheckDestroy: testAccCheckStackRoxPolicyWasDestroyed(resourceName),
+	})
+}
+
+func TestAccStackRoxPolicy_json(t *testing.T) {
+	resourceName := acctest.RandomWithPrefix("testacc-policy")
+	address := testAccStackRoxPolicyAddress(resourceName)
+
+	var policy stackrox.StoragePolicy
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStackRoxPolicyConfigJSON(resourceName, "MEDIUM_SEVERITY"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxPolicyExists(resourceName, &policy),
+					resource.TestCheckResourceAttrSet(address, "canonical_json"),
+					resource.TestCheckNoResourceAttr(address, "name"),
+				),
+			},
+			// Changes of the JSON update the policy.
+			{
+				Config: testAccStackRoxPolicyConfigJSON(resourceName, "HIGH_SEVERITY"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxPolicyExists(resourceName, &policy),
+					func(*terraform.State) error {
+						if policy.Severity != "HIGH_SEVERITY" {
+							return fmt.Errorf("expected severity HIGH_SEVERITY, got %s", policy.Severity)
+						}
+						return nil
+					},
 				),
 			},
 		},
@@ -73,6 +214,7 @@ func TestAccStackRoxPolicy_basic(t *testing.T) {
 
 func TestAccStackRoxPolicy_destroyIsIdempotent(t *testing.T) {
 	t.Parallel()
+	testAccPreCheck(t)
 
 	id := acctest.RandString(10)
 	data := &schema.ResourceData{}
@@ -105,8 +247,15 @@ func testAccCheckStackRoxPolicyResourceAttributes(resourceName string, policy *s
 
 		// verify policy criteria
 		resource.TestCheckResourceAttr(resourceName, "policy_criteria.#", "1")
-		resource.TestCheckResourceAttr(resourceName, "policy_criteria.0.cvss", stackRoxTerraformCvssFromMessage(policy.Fields.Cvss))
+		cvss, err := stackRoxTerraformNumericalPolicyFromMessage(policy.Fields.Cvss)
+		if err != nil {
+			return err
+		}
+		resource.TestCheckResourceAttr(resourceName, "policy_criteria.0.cvss", cvss)
 		resource.TestCheckResourceAttr(resourceName, "policy_criteria.0.privileged", stackRoxTerraformBoolFromMessage(policy.Fields.Privileged))
+		resource.TestCheckResourceAttr(resourceName, "policy_criteria.0.read_only_root_fs", stackRoxTerraformBoolFromMessage(policy.Fields.ReadOnlyRootFs))
+		resource.TestCheckResourceAttr(resourceName, "policy_criteria.0.user", policy.Fields.User)
+		resource.TestCheckResourceAttr(resourceName, "policy_criteria.0.container_resources.0.cpu_limit", "> 2")
 
 		return nil
 	}
@@ -125,7 +274,7 @@ func testAccCheckStackRoxPolicyExists(resourceName string, out *stackrox.Storage
 
 		cli := testAccClientWrap()
 
-		result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.BasicAuthContext(), res.Primary.ID)
+		result, resp, err := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		*out = result
 
@@ -170,10 +319,21 @@ resource "stackrox_policy" "%s" {
   policy_criteria {
     cvss = ">= 3"
     privileged = true
+    read_only_root_fs = false
+    drop_capabilities = ["NET_RAW"]
+    user = "0"
+
+    host_mount {
+      read_only = false
+    }
+
+    container_resources {
+      cpu_limit = "> 2"
+    }
   }
 }
 `
-	return fmt.Sprintf(config, testAccEndpoint(), testAccPassword(),
+	return fmt.Sprintf(config,
 		resourceName, resourceName, resourceName, resourceName,
 	)
 }
@@ -206,6 +366,9 @@ resource "stackrox_policy" "%s" {
   notifiers        = [
     stackrox_splunk_integration.%s.id
   ]
+  enforcement_actions = [
+    "SCALE_TO_ZERO_ENFORCEMENT"
+  ]
 
   policy_criteria {
     cvss = ">= 3"
@@ -213,11 +376,186 @@ resource "stackrox_policy" "%s" {
   }
 }
 `
-	return fmt.Sprintf(config, testAccEndpoint(), testAccPassword(),
+	return fmt.Sprintf(config,
 		resourceName, resourceName, resourceName, resourceName, resourceName,
 	)
 }
 
+func testAccStackRoxPolicyConfigImageCriteria(resourceName string) string {
+	const config = testAccProviderConfig + `
+resource "stackrox_policy" "%s" {
+  name             = "%s"
+  description      = "fake description"
+  rationale        = "fake rationale"
+  remediation      = "fake remediation"
+  disabled         = true
+  categories       = [
+    "DevOps Best Practices"
+  ]
+  lifecycle_stages = [
+    "BUILD",
+    "DEPLOY"
+  ]
+  severity         = "LOW_SEVERITY"
+
+  policy_criteria {
+    image_name {
+      registry = "docker.io"
+      tag      = "latest"
+    }
+    image_age_days = 90
+    scan_age_days  = 7
+    no_scan_exists = true
+    cve            = "CVE-2021-.*"
+    fixed_by       = ".*"
+
+    dockerfile_line {
+      instruction = "ADD"
+      value       = "https?://.*"
+    }
+
+    component {
+      name    = "openssl"
+      version = "1\\.1\\.1[a-j]"
+    }
+
+    required_label {
+      key = "owner"
+    }
+
+    env {
+      key    = "AWS_SECRET_ACCESS_KEY"
+      source = "RAW"
+    }
+  }
+}
+`
+	return fmt.Sprintf(config,
+		resourceName, resourceName,
+	)
+}
+
+func testAccStackRoxPolicyConfigProcessCriteria(resourceName string) string {
+	const config = testAccProviderConfig + `
+resource "stackrox_policy" "%s" {
+  name             = "%s"
+  description      = "fake description"
+  rationale        = "fake rationale"
+  remediation      = "fake remediation"
+  disabled         = true
+  categories       = [
+    "System Modification"
+  ]
+  lifecycle_stages = [
+    "RUNTIME"
+  ]
+  severity         = "HIGH_SEVERITY"
+
+  policy_criteria {
+    process {
+      name     = "bash|sh"
+      ancestor = "java"
+    }
+  }
+}
+`
+	return fmt.Sprintf(config,
+		resourceName, resourceName,
+	)
+}
+
+func testAccStackRoxPolicyConfigScope(resourceName, clusterID string) string {
+	const config = testAccProviderConfig + `
+resource "stackrox_kubernetes_cluster" "%s" {
+  name                 = "%s"
+  cluster_id           = "%s"
+  central_api_endpoint = "central.stackrox:443"
+  collection_method    = "KERNEL_MODULE"
+  runtime_support      = true
+}
+
+resource "stackrox_policy" "%s" {
+  name             = "%s"
+  description      = "fake description"
+  rationale        = "fake rationale"
+  remediation      = "fake remediation"
+  disabled         = true
+  categories       = [
+    "Security Best Practices"
+  ]
+  lifecycle_stages = [
+    "DEPLOY"
+  ]
+  severity         = "HIGH_SEVERITY"
+
+  scope {
+    cluster   = stackrox_kubernetes_cluster.%s.id
+    namespace = "team-.*"
+  }
+
+  scope {
+    label {
+      key   = "app"
+      value = "web"
+    }
+  }
+
+  exclusion {
+    name = "legacy"
+
+    deployment {
+      name = "legacy-app"
+
+      scope {
+        cluster   = stackrox_kubernetes_cluster.%s.id
+        namespace = "legacy"
+      }
+    }
+
+    expiration = "2099-12-31T00:00:00Z"
+  }
+
+  policy_criteria {
+    privileged = true
+  }
+}
+`
+	return fmt.Sprintf(config,
+		resourceName, resourceName, clusterID, resourceName, resourceName, resourceName, resourceName,
+	)
+}
+
+func testAccStackRoxPolicyConfigJSON(resourceName, severity string) string {
+	const config = testAccProviderConfig + `
+resource "stackrox_policy" "%s" {
+  policy_json = <<EOF
+{
+  "policies": [
+    {
+      "id": "a919ccaf-6b43-4160-ac5d-a405e1440a41",
+      "name": "%s",
+      "description": "fake description",
+      "rationale": "fake rationale",
+      "remediation": "fake remediation",
+      "disabled": true,
+      "categories": ["Privileges"],
+      "lifecycleStages": ["DEPLOY"],
+      "severity": "%s",
+      "fields": {
+        "privileged": true
+      },
+      "lastUpdated": "2021-03-01T10:00:00Z"
+    }
+  ]
+}
+EOF
+}
+`
+	return fmt.Sprintf(config,
+		resourceName, resourceName, severity,
+	)
+}
+
 func testAccCheckStackRoxPolicyWasDestroyed(resourceName string) resource.TestCheckFunc {
 	return func(state *terraform.State) error {
 		res, ok := state.RootModule().Resources[testAccStackRoxPolicyAddress(resourceName)]
@@ -231,7 +569,7 @@ func testAccCheckStackRoxPolicyWasDestroyed(resourceName string) resource.TestCh
 
 		cli := testAccClientWrap()
 
-		_, resp, _ := cli.PolicyServiceApi.GetPolicy(cli.BasicAuthContext(), res.Primary.ID)
+		_, resp, _ := cli.PolicyServiceApi.GetPolicy(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
 			return fmt.Errorf("remote policy resource was not destroyed. status: %v", resp.Status)
@@ -0,0 +1,150 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+// The sensor upgrade config is a singleton in Central, so the resource always has the same ID.
+const stackRoxSensorUpgradeConfigID = "sensor-upgrade-config"
+
+func resourceStackRoxSensorUpgradeConfig() *schema.Resource {
+	return &schema.Resource{
+		Create:        stackRoxSensorUpgradeConfigCreate,
+		Read:          stackRoxSensorUpgradeConfigRead,
+		Update:        stackRoxSensorUpgradeConfigUpdate,
+		Delete:        stackRoxSensorUpgradeConfigDelete,
+		Importer:      stackRoxSensorUpgradeConfigImporter(),
+		CustomizeDiff: checkCentralFeatures("stackrox_sensor_upgrade_config"),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"enable_auto_upgrade": {
+				Type:     schema.TypeBool,
+				Required: true,
+			},
+		},
+	}
+}
+
+func stackRoxSensorUpgradeConfigCreate(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxSensorUpgradeConfigCreate")
+
+	ctx, cancel := meta.(ClientWrap).TimeoutContext(data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	if err := stackRoxSensorUpgradeConfigPut(ctx, data, meta); err != nil {
+		return err
+	}
+
+	data.SetId(stackRoxSensorUpgradeConfigID)
+	return stackRoxSensorUpgradeConfigRead(data, meta)
+}
+
+func stackRoxSensorUpgradeConfigRead(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxSensorUpgradeConfigRead")
+
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.SensorUpgradeServiceApi.GetSensorUpgradeConfig(cli.AuthContext(ctx))
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
+	if err != nil {
+		return err
+	}
+
+	// Update the local state.
+	return stackRoxSensorUpgradeConfigSetState(data, result.Config)
+}
+
+func stackRoxSensorUpgradeConfigUpdate(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxSensorUpgradeConfigUpdate")
+
+	if !data.HasChange("enable_auto_upgrade") {
+		return stackRoxSensorUpgradeConfigRead(data, meta)
+	}
+
+	ctx, cancel := meta.(ClientWrap).TimeoutContext(data.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
+	if err := stackRoxSensorUpgradeConfigPut(ctx, data, meta); err != nil {
+		return err
+	}
+
+	return stackRoxSensorUpgradeConfigRead(data, meta)
+}
+
+// The config can't be deleted. So, the setting in Central is left as it is and
+// the resource is only removed from the state.
+func stackRoxSensorUpgradeConfigDelete(data *schema.ResourceData, meta interface{}) error {
+	debug("calling stackRoxSensorUpgradeConfigDelete")
+
+	return nil
+}
+
+func stackRoxSensorUpgradeConfigImporter() *schema.ResourceImporter {
+	return &schema.ResourceImporter{
+		State: stackRoxSensorUpgradeConfigImportState,
+	}
+}
+
+func stackRoxSensorUpgradeConfigImportState(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	debug("calling stackRoxSensorUpgradeConfigImportState")
+
+	data.SetId(stackRoxSensorUpgradeConfigID)
+	if err := stackRoxSensorUpgradeConfigRead(data, meta); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{data}, nil
+}
+
+func stackRoxSensorUpgradeConfigPut(ctx context.Context, data *schema.ResourceData, meta interface{}) error {
+	message := stackrox.V1UpdateSensorUpgradeConfigRequest{
+		Config: stackrox.StorageSensorUpgradeConfig{
+			EnableAutoUpgrade: data.Get("enable_auto_upgrade").(bool),
+		},
+	}
+
+	logMessage(message)
+
+	cli := meta.(ClientWrap)
+	result, resp, err := cli.SensorUpgradeServiceApi.UpdateSensorUpgradeConfig(cli.AuthContext(ctx), message)
+	logResult(result, resp, err)
+	err = newAPIError(resp, err)
+
+	return err
+}
+
+func stackRoxSensorUpgradeConfigSetState(data *schema.ResourceData, src stackrox.StorageSensorUpgradeConfig) error {
+	if err := data.Set("enable_auto_upgrade", src.EnableAutoUpgrade); err != nil {
+		return err
+	}
+	return nil
+}
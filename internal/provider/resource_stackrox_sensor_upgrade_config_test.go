@@ -0,0 +1,115 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+
+	"github.com/splunk/terraform-provider-stackrox/internal/provider/stackrox"
+)
+
+const testAccStackRoxSensorUpgradeConfigAddress = "stackrox_sensor_upgrade_config.test"
+
+// TestAccStackRoxSensorUpgradeConfig_basic exercises the code in real plan, apply,
+// refresh, and destroy life cycles for the `stackrox_sensor_upgrade_config` resource.
+func TestAccStackRoxSensorUpgradeConfig_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccRestoreStackRoxSensorUpgradeConfig(t)
+		},
+		Providers: testAccProviders(),
+		Steps: []resource.TestStep{
+			// Exercise the plan, apply, refresh, and destroy life cycles.
+			{
+				Config: testAccStackRoxSensorUpgradeConfigConfig(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxSensorUpgradeConfig(false),
+					resource.TestCheckResourceAttr(testAccStackRoxSensorUpgradeConfigAddress, "enable_auto_upgrade", "false"),
+				),
+			},
+			// Exercise the import life cycle.
+			{
+				ResourceName:      testAccStackRoxSensorUpgradeConfigAddress,
+				Config:            testAccStackRoxProviderConfig(),
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     stackRoxSensorUpgradeConfigID,
+			},
+			// Update the setting.
+			{
+				Config: testAccStackRoxSensorUpgradeConfigConfig(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckStackRoxSensorUpgradeConfig(true),
+					resource.TestCheckResourceAttr(testAccStackRoxSensorUpgradeConfigAddress, "enable_auto_upgrade", "true"),
+				),
+			},
+		},
+	})
+}
+
+// testAccRestoreStackRoxSensorUpgradeConfig restores the setting Central had before the test, since destroying the
+// resource leaves the setting as it is.
+func testAccRestoreStackRoxSensorUpgradeConfig(t *testing.T) {
+	cli := testAccClientWrap()
+
+	original, _, err := cli.SensorUpgradeServiceApi.GetSensorUpgradeConfig(cli.AuthContext(context.Background()))
+	if err != nil {
+		t.Fatalf("error fetching resource: %v", err)
+	}
+
+	t.Cleanup(func() {
+		message := stackrox.V1UpdateSensorUpgradeConfigRequest{Config: original.Config}
+		_, _, err := cli.SensorUpgradeServiceApi.UpdateSensorUpgradeConfig(cli.AuthContext(context.Background()), message)
+		if err != nil {
+			t.Errorf("error restoring enable_auto_upgrade to %v: %v", original.Config.EnableAutoUpgrade, err)
+		}
+	})
+}
+
+func testAccCheckStackRoxSensorUpgradeConfig(expected bool) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		cli := testAccClientWrap()
+
+		result, _, err := cli.SensorUpgradeServiceApi.GetSensorUpgradeConfig(cli.AuthContext(context.Background()))
+		if err != nil {
+			return fmt.Errorf("error fetching resource: %v", err)
+		}
+
+		if result.Config.EnableAutoUpgrade != expected {
+			return fmt.Errorf("enable_auto_upgrade is %v, expected %v", result.Config.EnableAutoUpgrade, expected)
+		}
+
+		return nil
+	}
+}
+
+func testAccStackRoxSensorUpgradeConfigConfig(enableAutoUpgrade bool) string {
+	const config = testAccProviderConfig + `
+resource "stackrox_sensor_upgrade_config" "test" {
+  enable_auto_upgrade = %s
+}
+`
+
+	return fmt.Sprintf(config, strconv.FormatBool(enableAutoUpgrade))
+}
@@ -17,9 +17,8 @@
 package provider
 
 import (
-	"fmt"
-	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/antihax/optional"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -34,6 +33,12 @@ func resourceStackRoxSplunkIntegration() *schema.Resource {
 		Update:   stackRoxSplunkIntegrationUpdate,
 		Delete:   stackRoxSplunkIntegrationDelete,
 		Importer: stackRoxSplunkIntegrationImporter(),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -69,6 +74,10 @@ func resourceStackRoxSplunkIntegration() *schema.Resource {
 func stackRoxSplunkIntegrationCreate(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxSplunkIntegrationCreate")
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
 	message := stackrox.StorageNotifier{
 		Name:       data.Get("name").(string),
 		UiEndpoint: data.Get("ui_endpoint").(string),
@@ -85,9 +94,9 @@ func stackRoxSplunkIntegrationCreate(data *schema.ResourceData, meta interface{}
 
 	logMessage(message)
 
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.NotifierServiceApi.PostNotifier(cli.BasicAuthContext(), message)
+	result, resp, err := cli.NotifierServiceApi.PostNotifier(cli.AuthContext(ctx), message)
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 	if err != nil {
 		return err
 	}
@@ -101,18 +110,26 @@ func stackRoxSplunkIntegrationCreate(data *schema.ResourceData, meta interface{}
 func stackRoxSplunkIntegrationRead(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxSplunkIntegrationRead")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
-	result, resp, err := cli.NotifierServiceApi.GetNotifier(cli.BasicAuthContext(), data.Id())
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
+	result, resp, err := cli.NotifierServiceApi.GetNotifier(cli.AuthContext(ctx), data.Id())
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// If the resource does not exist, inform Terraform. We want to immediately
 	// return here to prevent further processing.
-	if resp != nil && resp.StatusCode == http.StatusNotFound {
+	if isNotFound(err) {
 		data.SetId("")
 		return nil
 	}
 
+	if err != nil {
+		return err
+	}
+
 	// Update the local state.
 	stackRoxSplunkIntegrationSetState(data, result)
 	return nil
@@ -127,22 +144,22 @@ func stackRoxSplunkIntegrationUpdate(data *schema.ResourceData, meta interface{}
 func stackRoxSplunkIntegrationDelete(data *schema.ResourceData, meta interface{}) error {
 	debug("calling stackRoxSplunkIntegrationDelete: " + data.Id())
 
+	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
 	// Attempt to delete from an upstream API.
 	// data.SetId("") is automatically called assuming delete returns no errors.
-	cli := meta.(ClientWrap)
-	result, resp, err := cli.NotifierServiceApi.DeleteNotifier(cli.BasicAuthContext(), data.Id(), &stackrox.DeleteNotifierOpts{Force: optional.NewBool(true)})
+	result, resp, err := cli.NotifierServiceApi.DeleteNotifier(cli.AuthContext(ctx), data.Id(), &stackrox.DeleteNotifierOpts{Force: optional.NewBool(true)})
 	logResult(result, resp, err)
+	err = newAPIError(resp, err)
 
 	// Destroy should be idempotent. The cluster API returns 404 when the resource isn't found.
-	if resp != nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound) {
-		return nil
-	}
-
-	if err != nil {
+	if err != nil && !isNotFound(err) {
 		return err
 	}
 
-	return fmt.Errorf(resp.Status)
+	return nil
 }
 
 func stackRoxSplunkIntegrationImporter() *schema.ResourceImporter {
@@ -154,20 +171,19 @@ func stackRoxSplunkIntegrationImporter() *schema.ResourceImporter {
 func stackRoxSplunkIntegrationImportState(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	debug("calling stackRoxSplunkIntegrationImportState")
 
-	// Attempt to read from an upstream API.
 	cli := meta.(ClientWrap)
+	ctx, cancel := cli.TimeoutContext(data.Timeout(schema.TimeoutRead))
+	defer cancel()
+
+	// Attempt to read from an upstream API.
 	id := data.Id()
-	result, resp, err := cli.NotifierServiceApi.GetNotifier(cli.BasicAuthContext(), id)
+	result, resp, err := cli.NotifierServiceApi.GetNotifier(cli.AuthContext(ctx), id)
 	logResult(result, resp, err)
-
+	err = newAPIError(resp, err)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(resp.Status)
-	}
-
 	// Import the resource.
 	newData := &schema.ResourceData{}
 	newData.SetType("stackrox_splunk_integration")
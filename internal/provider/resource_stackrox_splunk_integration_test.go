@@ -17,6 +17,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -39,6 +40,7 @@ func TestAccStackRoxSplunkIntegration_basic(t *testing.T) {
 	var notifier stackrox.StorageNotifier
 
 	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
 		Providers: testAccProviders(),
 		Steps: []resource.TestStep{
 			// Exercise the plan, apply, refresh, and destroy life cycles.
@@ -67,6 +69,7 @@ func TestAccStackRoxSplunkIntegration_basic(t *testing.T) {
 
 func TestAccStackRoxSplunkIntegration_destroyIsIdempotent(t *testing.T) {
 	t.Parallel()
+	testAccPreCheck(t)
 
 	id := acctest.RandString(10)
 	data := &schema.ResourceData{}
@@ -105,7 +108,7 @@ func testAccCheckStackRoxSplunkIntegrationExists(resourceName string, out *stack
 
 		cli := testAccClientWrap()
 
-		result, resp, err := cli.NotifierServiceApi.GetNotifier(cli.BasicAuthContext(), res.Primary.ID)
+		result, resp, err := cli.NotifierServiceApi.GetNotifier(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		*out = result
 
@@ -133,7 +136,7 @@ resource "stackrox_splunk_integration" "%s" {
 }
 `
 
-	return fmt.Sprintf(config, testAccEndpoint(), testAccPassword(), resourceName, resourceName)
+	return fmt.Sprintf(config, resourceName, resourceName)
 }
 
 func testAccCheckStackRoxSplunkIntegrationWasDestroyed(resourceName string) resource.TestCheckFunc {
@@ -149,7 +152,7 @@ func testAccCheckStackRoxSplunkIntegrationWasDestroyed(resourceName string) reso
 
 		cli := testAccClientWrap()
 
-		_, resp, _ := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.BasicAuthContext(), res.Primary.ID)
+		_, resp, _ := cli.ImageIntegrationServiceApi.GetImageIntegration(cli.AuthContext(context.Background()), res.Primary.ID)
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
 			return fmt.Errorf("remote splunk-integration resource was not destroyed. status: %v", resp.Status)
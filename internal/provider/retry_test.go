@@ -0,0 +1,141 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryClient(maxRetries int) *http.Client {
+	transport := newRetryTransport(cleanhttp.DefaultTransport(), maxRetries, 10*time.Millisecond)
+	transport.minWait = time.Millisecond
+	return &http.Client{Transport: transport}
+}
+
+func TestRetryTransport_retriesIdempotentRequests(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+	assert.NoError(t, err)
+
+	resp, err := testRetryClient(4).Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+}
+
+func TestRetryTransport_doesNotModifyRequest(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+	assert.NoError(t, err)
+	body := req.Body
+
+	resp, err := testRetryClient(2).Transport.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 3, count)
+	assert.True(t, body == req.Body, "the body of the request was replaced")
+}
+
+func TestRetryTransport_givesUp(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := testRetryClient(2).Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 3, count)
+}
+
+func TestRetryTransport_doesNotRetryNonIdempotentResponses(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	resp, err := testRetryClient(4).Post(server.URL, "application/json", strings.NewReader("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, count)
+}
+
+func TestRetryTransport_retriesNonIdempotentDialErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	start := time.Now()
+	_, err := testRetryClient(2).Post(url, "application/json", strings.NewReader("{}"))
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) >= 3*time.Millisecond, "expected the request to be retried")
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	wait, ok := retryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, wait)
+
+	wait, ok = retryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = retryAfter("soon")
+	assert.False(t, ok)
+}
@@ -0,0 +1,63 @@
+/*
+   Copyright 2021 Splunk Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// tlsSettings holds the TLS related provider arguments.
+type tlsSettings struct {
+	CACertPEM          string
+	ClientCertPEM      string
+	ClientKeyPEM       string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// newTLSConfig builds the TLS configuration used to connect to Central.
+// It returns nil when all settings are left at their defaults.
+func newTLSConfig(settings tlsSettings) (*tls.Config, error) {
+	if settings == (tlsSettings{}) {
+		return nil, nil
+	}
+
+	result := &tls.Config{
+		ServerName:         settings.ServerName,
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+	}
+
+	if settings.CACertPEM != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(settings.CACertPEM)) {
+			return nil, fmt.Errorf("no valid certificates found in the CA certificate bundle")
+		}
+		result.RootCAs = pool
+	}
+
+	if settings.ClientCertPEM != "" || settings.ClientKeyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(settings.ClientCertPEM), []byte(settings.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+
+	return result, nil
+}